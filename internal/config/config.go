@@ -3,38 +3,131 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/adrg/xdg"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+
+	"github.com/lakrizz/logsync/internal/frontmatter"
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+const (
+	IngressNgrok = "ngrok"
+	IngressHTTP  = "http"
+	IngressUnix  = "unix"
+)
+
+const (
+	// ModeWebhook syncs on every push delivered by a github webhook (default)
+	ModeWebhook = "webhook"
+	// ModePoll periodically fetches the logseq repository, no inbound connection is needed
+	ModePoll = "poll"
+)
+
+const (
+	// LinkFormatMarkdown rewrites links to published pages to plain markdown links (default)
+	LinkFormatMarkdown = "markdown"
+	// LinkFormatRelref rewrites links to published pages to hugo's relref shortcode
+	LinkFormatRelref = "relref"
+)
+
+const (
+	// UnpublishedLinksText replaces links to unpublished pages with their label
+	UnpublishedLinksText = "text"
+	// UnpublishedLinksKeep leaves links to unpublished pages as they are
+	UnpublishedLinksKeep = "keep"
+	// UnpublishedLinksRemove removes links to unpublished pages entirely
+	UnpublishedLinksRemove = "remove"
+)
+
+const (
+	// SlugTransliterate transliterates names to lower case ascii, e.g. "Über Go" becomes "uber-go" (default)
+	SlugTransliterate = "transliterate"
+	// SlugLower lower cases names and keeps their letters, e.g. "Über Go" becomes "über-go"
+	SlugLower = "lower"
+	// SlugPreserve keeps the case and the letters of names, e.g. "Über Go" becomes "Über-Go"
+	SlugPreserve = "preserve"
 )
 
 var (
 	errConfigNotFound = errors.New("could not find config")
+	errUnknownMode    = errors.New("unknown mode, use either 'webhook' or 'poll'")
+	errUnknownIngress = errors.New("unknown ingress type, use either 'ngrok', 'http' or 'unix'")
+	errUnknownSlug    = errors.New("unknown slug strategy, use either 'transliterate', 'lower' or 'preserve'")
+	errUnknownFormat  = errors.New("unknown filename format, use either 'triple-lowbar' or 'legacy'")
 )
 
 type Config struct {
 	Git *struct {
 		Token              string `json:"token"`
 		LogseqRepoURL      string `json:"logseq_repo_url"`
+		LogseqRepoPath     string `json:"logseq_repo_path"` // where the logseq repository is cloned to, defaults to ./git/logseq
 		HugoRepoPath       string `json:"hugo_repo_path"`
 		PrivateKeyPath     string `json:"private_key_path"`
 		PrivateKeyPassword string `json:"private_key_password"`
 		Username           string `json:"username"`
 		Email              string `json:"email"`
+		WebhookSecret      string `json:"webhook_secret"`
 	} `json:"git"`
 
+	// Ngrok is only needed for the ngrok ingress
 	Ngrok *struct {
 		AuthToken string `json:"auth_token"`
 	} `json:"ngrok"`
+
+	Ingress *Ingress `json:"ingress"`
+
+	Mode string `json:"mode"`
+	Poll *struct {
+		Interval Duration `json:"interval"`
+		Jitter   Duration `json:"jitter"`
+	} `json:"poll"`
+
+	// Logseq describes how the logseq graph stores its pages
+	Logseq *Logseq `json:"logseq"`
+
 	Mappings []*Mapping `json:"mappings"`
 }
 
+type Logseq struct {
+	FileNameFormat string `json:"file_name_format"` // triple-lowbar or legacy, overrides :file/name-format of logseq/config.edn
+}
+
+// Ingress describes how the webhook handler is exposed to github
+type Ingress struct {
+	Type        string `json:"type"`          // ngrok (default), http or unix
+	Address     string `json:"address"`       // listen address of the http ingress, e.g. ":8080"
+	TLSCertFile string `json:"tls_cert_file"` // optional, the http ingress serves https if both cert and key are given
+	TLSKeyFile  string `json:"tls_key_file"`
+	SocketPath  string `json:"socket_path"` // path of the unix domain socket
+	PublicURL   string `json:"public_url"`  // url github delivers webhooks to, required for http and unix
+}
+
 type Mapping struct {
-	Options     *Options       `json:"options"`
-	Frontmatter map[string]any `json:"frontmatter"`
-	Source      string         `json:"source"`
-	Target      string         `json:"target"`
+	Options           *Options       `json:"options"`
+	Frontmatter       map[string]any `json:"frontmatter"`
+	FrontmatterFormat string         `json:"frontmatter_format"` // toml (default), yaml or json
+	Source            string         `json:"source"`
+	Target            string         `json:"target"`
+
+	// Property publishes only pages with this `key:: value` page property, e.g. `public:: true`,
+	// pages that lose the property are unpublished
+	Property string `json:"property"`
+
+	// Journal publishes the journals the source matches as dated posts
+	Journal *Journal `json:"journal"`
+
+	// Properties maps the page properties that are added to the front matter to their front matter key,
+	// all other page properties are dropped, an empty key keeps the name of the property
+	Properties map[string]string `json:"properties"`
+
+	template string // target template of a mapping returned by Match
 }
 
 type Options struct {
@@ -46,14 +139,35 @@ type Options struct {
 	RemoveEmptyTrails   bool   `json:"remove_empty_trails,omitempty"`
 	UnindentFirstLevel  bool   `json:"unindent_first_level,omitempty"`
 	IncludeAttachments  bool   `json:"include_attachments,omitempty"`
+	RemoveAttachments   bool   `json:"remove_attachments,omitempty"`
+	RewriteLinks        bool   `json:"rewrite_links,omitempty"`
+	LinkFormat          string `json:"link_format,omitempty"`       // markdown (default) or relref
+	UnpublishedLinks    string `json:"unpublished_links,omitempty"` // text, keep or remove
+	Slug                string `json:"slug,omitempty"`              // transliterate (default), lower or preserve
+
+	ResolveBlockReferences bool `json:"resolve_block_references,omitempty"`
+	LinkBlockReferences    bool `json:"link_block_references,omitempty"`
 
 	// these values should be available to all options but need no manual work
-	LogseqRepositoryPath string `json:"-"`
-	HugoRepositoryPath   string `json:"-"`
+	LogseqRepositoryPath string            `json:"-"`
+	FileNameFormat       string            `json:"-"` // filename format of the logseq graph, if it's set in the config, see Graph
+	HugoRepositoryPath   string            `json:"-"`
+	MappedPages          map[string]string `json:"-"` // source -> target of every published mapping of a single file
+	MappedPatterns       []*Mapping        `json:"-"` // every published mapping of a source pattern or property
+
+	graph *logseq.GraphConfig // settings of the logseq graph, see LoadGraph
 }
 
-func Load() (*Config, error) {
-	filename := filepath.Join(xdg.ConfigHome, "logsync", "config.json")
+// DefaultPath returns the location of the config file in the XDG config directory
+func DefaultPath() string {
+	return filepath.Join(xdg.ConfigHome, "logsync", "config.json")
+}
+
+// Load reads the config from the given file, an empty filename loads the config from DefaultPath
+func Load(filename string) (*Config, error) {
+	if filename == "" {
+		filename = DefaultPath()
+	}
 
 	dat, err := os.ReadFile(filename)
 	if err != nil {
@@ -69,6 +183,30 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.Mode == "" {
+		cfg.Mode = ModeWebhook
+	}
+
+	if cfg.Ingress == nil {
+		cfg.Ingress = &Ingress{}
+	}
+
+	if cfg.Ingress.Type == "" {
+		cfg.Ingress.Type = IngressNgrok
+	}
+
+	if cfg.Logseq == nil {
+		cfg.Logseq = &Logseq{}
+	}
+
+	if cfg.Git != nil && cfg.Git.LogseqRepoPath == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Git.LogseqRepoPath = filepath.Join(wd, "git", "logseq")
+	}
+
 	err = cfg.SetStaticValuesForAllOptions()
 	if err != nil {
 		return nil, err
@@ -77,13 +215,42 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// IsValid checks whether the config contains everything that's needed to serve in the configured mode
 func (c *Config) IsValid() (bool, error) {
-	errs := make([]error, 8) // we have 8 required config fields, might as well reserve the mem now
+	errs := c.validateRepositories()
+	errs = append(errs, c.validateMappings()...)
 
-	if c.Git.Token == "" {
-		errs = append(errs, errors.New("github token not"))
+	if c.Mode == ModeWebhook && c.Git.Token == "" {
+		errs = append(errs, errors.New("github token not set"))
 	}
 
+	if c.Mode != ModeWebhook && c.Mode != ModePoll {
+		errs = append(errs, errUnknownMode)
+	}
+
+	if c.Mode == ModeWebhook {
+		errs = append(errs, c.validateIngress()...)
+	}
+
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return true, nil
+}
+
+// IsValidForSync checks whether the config contains everything that's needed for a one-shot sync
+func (c *Config) IsValidForSync() (bool, error) {
+	errs := c.validateRepositories()
+	errs = append(errs, c.validateMappings()...)
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return true, nil
+}
+
+func (c *Config) validateRepositories() []error {
+	errs := make([]error, 0, 8) // we have 8 required config fields, might as well reserve the mem now
+
 	if c.Git.HugoRepoPath == "" {
 		errs = append(errs, errors.New("hugo repository path not given"))
 	}
@@ -100,26 +267,164 @@ func (c *Config) IsValid() (bool, error) {
 		errs = append(errs, errors.New("logseq repository url not set"))
 	}
 
-	if c.Git.PrivateKeyPath == "" {
-		errs = append(errs, errors.New("private key path not set"))
+	// local and https repositories are accessed without the key
+	if c.Git.PrivateKeyPath == "" && (isSSH(c.Git.LogseqRepoURL) || isSSH(c.hugoRemoteURL())) {
+		errs = append(errs, errors.New("private key path not set, it's needed for ssh remotes"))
 	}
 
-	if len(errs) == 0 {
-		return false, errors.Join(errs...)
+	return errs
+}
+
+// hugoRemoteURL returns the url of the origin of the hugo repository, if it's already cloned
+func (c *Config) hugoRemoteURL() string {
+	repo, err := git.PlainOpen(c.Git.HugoRepoPath)
+	if err != nil {
+		return ""
 	}
-	return true, nil
+
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// isSSH reports whether url is accessed via ssh, e.g. ssh://git@github.com/a/b.git or git@github.com:a/b.git
+func isSSH(url string) bool {
+	if url == "" {
+		return false
+	}
+
+	endpoint, err := transport.NewEndpoint(url)
+	return err == nil && endpoint.Protocol == "ssh"
+}
+
+func (c *Config) validateMappings() []error {
+	errs := make([]error, 0)
+
+	if c.Logseq != nil && !slices.Contains([]string{"", logseq.FileNameFormatTripleLowbar, logseq.FileNameFormatLegacy}, c.Logseq.FileNameFormat) {
+		errs = append(errs, errUnknownFormat)
+	}
+
+	for _, mapping := range c.Mappings {
+		if !frontmatter.IsValidFormat(mapping.FrontmatterFormat) {
+			errs = append(errs, fmt.Errorf("mapping %s: unknown front matter format %q", mapping.Source, mapping.FrontmatterFormat))
+		}
+
+		if mapping.Options != nil && !slices.Contains([]string{"", SlugTransliterate, SlugLower, SlugPreserve}, mapping.Options.Slug) {
+			errs = append(errs, fmt.Errorf("mapping %s: %w", mapping.Source, errUnknownSlug))
+		}
+
+		if mapping.IsPattern() {
+			err := mapping.validatePattern()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mapping %s: %w", mapping.Source, err))
+			}
+		}
+
+		if mapping.Journal != nil {
+			err := mapping.validateJournal()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mapping %s: %w", mapping.Source, err))
+			}
+		}
+
+		if mapping.Property != "" {
+			err := mapping.validateProperty()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mapping %s: %w", mapping.Source, err))
+			}
+		}
+	}
+
+	return errs
 }
 
+func (c *Config) validateIngress() []error {
+	errs := make([]error, 0)
+
+	switch c.Ingress.Type {
+	case IngressNgrok:
+		if c.Ngrok == nil || c.Ngrok.AuthToken == "" {
+			errs = append(errs, errors.New("ngrok auth token not set"))
+		}
+	case IngressHTTP:
+		if c.Ingress.Address == "" {
+			errs = append(errs, errors.New("ingress address not set"))
+		}
+		if (c.Ingress.TLSCertFile == "") != (c.Ingress.TLSKeyFile == "") {
+			errs = append(errs, errors.New("ingress needs both a tls certificate and key file"))
+		}
+	case IngressUnix:
+		if c.Ingress.SocketPath == "" {
+			errs = append(errs, errors.New("ingress socket path not set"))
+		}
+	default:
+		errs = append(errs, errUnknownIngress)
+	}
+
+	if c.Ingress.Type != IngressNgrok && c.Ingress.PublicURL == "" {
+		errs = append(errs, errors.New("ingress public url not set"))
+	}
+
+	return errs
+}
+
+// SetStaticValuesForAllOptions fills the values of all mapping options that are derived from the rest of the config
+// it's called by Load, afterwards the config is read-only
 func (c *Config) SetStaticValuesForAllOptions() error {
+	mappedPages := make(map[string]string)
+	mappedPatterns := make([]*Mapping, 0)
+	for _, mapping := range c.Mappings {
+		if mapping.Options == nil {
+			mapping.Options = &Options{}
+		}
+
+		if mapping.Options.Recursive && mapping.Options.RecursionSkipSource {
+			// the source of this mapping is never published
+			continue
+		}
+
+		// the posts of journals can't be linked to, they're named after their content
+		if mapping.Journal != nil {
+			continue
+		}
+
+		// the pages of patterns and property mappings are only known once the logseq repository is read
+		if mapping.IsPattern() || mapping.Property != "" {
+			mappedPatterns = append(mappedPatterns, mapping)
+			continue
+		}
+		mappedPages[mapping.Source] = mapping.Target
+	}
+
 	for _, mapping := range c.Mappings {
 		mapping.Options.HugoRepositoryPath = c.Git.HugoRepoPath
+		mapping.Options.LogseqRepositoryPath = c.Git.LogseqRepoPath
+		if c.Logseq != nil {
+			mapping.Options.FileNameFormat = c.Logseq.FileNameFormat
+		}
+		mapping.Options.MappedPages = mappedPages
+		mapping.Options.MappedPatterns = mappedPatterns
 	}
 
 	return nil
 }
 
-func (c *Config) SetLogseqRepositoryPath(path string) {
-	for _, mapping := range c.Mappings {
-		mapping.Options.LogseqRepositoryPath = path
+// PollInterval returns the configured polling interval and jitter, falling back to sane defaults
+func (c *Config) PollInterval() (time.Duration, time.Duration) {
+	interval, jitter := 5*time.Minute, 30*time.Second
+	if c.Poll == nil {
+		return interval, jitter
+	}
+
+	if c.Poll.Interval > 0 {
+		interval = time.Duration(c.Poll.Interval)
 	}
+
+	if c.Poll.Jitter > 0 {
+		jitter = time.Duration(c.Poll.Jitter)
+	}
+
+	return interval, jitter
 }
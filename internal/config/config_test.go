@@ -0,0 +1,36 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+)
+
+func TestPrivateKeyIsOnlyNeededForSSH(t *testing.T) {
+	tests := []struct {
+		url   string
+		key   string
+		valid bool
+	}{
+		{url: "https://github.com/lakrizz/logseq.git", valid: true},
+		{url: "/srv/git/logseq.git", valid: true},
+		{url: "git@github.com:lakrizz/logseq.git"},
+		{url: "ssh://git@github.com/lakrizz/logseq.git"},
+		{url: "git@github.com:lakrizz/logseq.git", key: "/home/logsync/.ssh/id_ed25519", valid: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{}
+		err := json.Unmarshal([]byte(`{"git": {"hugo_repo_path": "`+t.TempDir()+`", "username": "logsync", "email": "logsync@example.com"}, "mode": "poll"}`), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Git.LogseqRepoURL, cfg.Git.PrivateKeyPath = tt.url, tt.key
+
+		valid, err := cfg.IsValidForSync()
+		if valid != tt.valid {
+			t.Errorf("%s (key %q): got valid %v, want %v (%v)", tt.url, tt.key, valid, tt.valid, err)
+		}
+	}
+}
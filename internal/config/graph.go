@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+// Graph returns the settings of the logseq graph, i.e. its logseq/config.edn with the values of this config
+// taking precedence, they're read once per sync by LoadGraph, options that weren't loaded get the default settings
+func (o *Options) Graph() *logseq.GraphConfig {
+	if o.graph != nil {
+		return o.graph
+	}
+	return o.withGraphOverrides(logseq.DefaultGraphConfig(o.LogseqRepositoryPath))
+}
+
+// LoadGraph reads the logseq/config.edn of the logseq repository and hands it to the options of all mappings
+// it's called at the beginning of every sync, so changes to config.edn are picked up without a restart
+func (c *Config) LoadGraph() error {
+	graph, err := logseq.LoadConfig(c.Git.LogseqRepoPath)
+	if err != nil {
+		return fmt.Errorf("cannot load logseq config: %w", err)
+	}
+
+	for _, mapping := range c.Mappings {
+		if mapping.Options == nil {
+			mapping.Options = &Options{}
+		}
+		mapping.Options.graph = mapping.Options.withGraphOverrides(graph)
+	}
+
+	return nil
+}
+
+// withGraphOverrides returns graph with the values of this config taking precedence
+func (o *Options) withGraphOverrides(graph *logseq.GraphConfig) *logseq.GraphConfig {
+	if o.FileNameFormat == "" {
+		return graph
+	}
+
+	result := *graph
+	result.FileNameFormat = o.FileNameFormat
+	return &result
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	errJournalDate = errors.New("the target of a journal has to contain its date, e.g. content/posts/{{ .Date }}-{{ .Slug }}.md")
+	errJournalSlug = errors.New("the target of a tagged journal has to contain the slug of the post, e.g. content/posts/{{ .Date }}-{{ .Slug }}.md")
+
+	// dateTokens translates the tokens of logseq's date formats to go's reference time, longer tokens go first
+	dateTokens = []string{
+		"yyyy", "2006",
+		"yy", "06",
+		"MMMM", "January",
+		"MMM", "Jan",
+		"MM", "01",
+		"M", "1",
+		"dd", "02",
+		"d", "2",
+		"EEEE", "Monday",
+		"EEE", "Mon",
+		"E", "Mon",
+	}
+)
+
+// Journal publishes logseq journals as dated posts
+type Journal struct {
+	FileNameFormat string `json:"file_name_format"` // format of the journal filenames in logseq's notation, defaults to :journal/file-name-format of the graph
+	Tag            string `json:"tag"`              // if set, only blocks tagged with it are published, each as a post of its own
+}
+
+// Date returns the day of the journal stored in file, defaultFormat is used if the journal has no format of its own
+func (j *Journal) Date(file, defaultFormat string) (time.Time, bool) {
+	format := j.FileNameFormat
+	if format == "" {
+		format = defaultFormat
+	}
+
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	date, err := time.Parse(dateLayout(format), name)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// JournalDate returns the day of the journal stored in file, m has to be the mapping of a journal
+func (m *Mapping) JournalDate(file string) (time.Time, bool) {
+	return m.Journal.Date(file, m.options().Graph().JournalFileNameFormat)
+}
+
+// JournalTarget returns the target of the post with the given title, m has to be the mapping of a journal
+// returned by Match, n > 1 numbers posts of the same title, e.g. the second post titled Post gets the slug post-2
+func (m *Mapping) JournalTarget(title string, n int) (string, error) {
+	data := m.targetData(m.Source)
+	data.Title = title
+	data.Slug = Slugify(m.options().Slug, title)
+	if data.Slug == "" {
+		data.Slug = Slugify(m.options().Slug, data.File)
+	}
+
+	if n > 1 {
+		data.Title = fmt.Sprintf("%s %d", data.Title, n)
+		data.Slug = fmt.Sprintf("%s-%d", data.Slug, n)
+	}
+
+	tmpl := m.template
+	if tmpl == "" {
+		tmpl = m.Target
+	}
+	return executeTarget(m.Source, tmpl, data)
+}
+
+// validateJournal checks that the target of a journal keeps the posts of different days apart
+func (m *Mapping) validateJournal() error {
+	if !strings.Contains(m.Target, ".Date") {
+		return errJournalDate
+	}
+	if m.Journal.Tag != "" && !strings.Contains(m.Target, ".Slug") && !strings.Contains(m.Target, ".Title") {
+		return errJournalSlug
+	}
+	return nil
+}
+
+// dateLayout converts a date format of logseq, e.g. yyyy_MM_dd, to a layout of the time package
+func dateLayout(format string) string {
+	var layout strings.Builder
+	for len(format) > 0 {
+		matched := false
+		for i := 0; i < len(dateTokens); i += 2 {
+			if strings.HasPrefix(format, dateTokens[i]) {
+				layout.WriteString(dateTokens[i+1])
+				format = format[len(dateTokens[i]):]
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			layout.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return layout.String()
+}
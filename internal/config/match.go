@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gosimple/slug"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+var (
+	errPatternTarget = errors.New("the target of a source pattern has to be a template, e.g. content/books/{{ .Slug }}.md")
+	errPropertyKey   = errors.New("the property needs a key, e.g. public:: true")
+
+	// targetFuncs are available in target templates
+	targetFuncs = template.FuncMap{"slug": slug.Make}
+)
+
+// TargetData is passed to the target template of a mapping
+type TargetData struct {
+	Name      string // name of the page, including its namespace, e.g. books/Dune, the title:: property takes precedence over the filename
+	Namespace string // namespace of the page, e.g. books
+	Title     string // name of the page without its namespace, e.g. Dune
+	Slug      string // slug of the title, e.g. dune
+	File      string // filename without extension, e.g. books___Dune
+	Date      string // day of a journal, e.g. 2024-06-12
+}
+
+// IsNamespace reports whether the source of the mapping is a logseq namespace like books/* instead of a file
+func (m *Mapping) IsNamespace() bool {
+	return path.Ext(m.Source) != ".md"
+}
+
+// IsPattern reports whether the source of the mapping matches more than a single file
+func (m *Mapping) IsPattern() bool {
+	return m.Journal != nil || m.IsNamespace() || strings.ContainsAny(m.Source, `*?[\`)
+}
+
+// Match returns the mapping of file, for patterns it's a copy of m with the actual source and target of file
+// a mapping with a property only matches pages that have this property, hidden pages never match
+func (m *Mapping) Match(file string) (*Mapping, bool) {
+	return m.match(m.options().LogseqRepositoryPath, file)
+}
+
+// MatchSource returns the mapping of file like Match, but ignores the property of the mapping
+// e.g. to unpublish a page that was removed or lost its property
+func (m *Mapping) MatchSource(file string) (*Mapping, bool) {
+	if !m.IsPattern() {
+		return m, m.Source == file
+	}
+
+	if !m.matches(file) {
+		return nil, false
+	}
+
+	result := *m
+	result.Source = file
+	result.template = m.Target
+
+	data := m.targetData(file)
+	if m.Journal != nil {
+		if data.Date == "" {
+			return nil, false
+		}
+
+		// the posts of a journal are only known once it's parsed, so a journal has no target of its own,
+		// see JournalTarget
+		result.Target = ""
+		return &result, true
+	}
+
+	target, err := executeTarget(m.Source, m.Target, data)
+	if err != nil {
+		return nil, false
+	}
+
+	result.Target = target
+	return &result, true
+}
+
+// Expand returns source -> target of all pages below the logseq repository root that the mapping matches
+func (m *Mapping) Expand(root string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, directory := range []string{"pages", "journals"} {
+		entries, err := os.ReadDir(filepath.Join(root, directory))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			mapping, ok := m.match(root, path.Join(directory, entry.Name()))
+			if ok {
+				result[mapping.Source] = mapping.Target
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Match returns the mapping of file, a mapping of exactly this file takes precedence over patterns,
+// which are tried in the order of the config
+func (c *Config) Match(file string) (*Mapping, bool) {
+	for _, mapping := range c.Mappings {
+		if mapping.IsPattern() || mapping.Source != file {
+			continue
+		}
+		if result, ok := mapping.Match(file); ok {
+			return result, true
+		}
+	}
+
+	for _, mapping := range c.Mappings {
+		if !mapping.IsPattern() {
+			continue
+		}
+		if result, ok := mapping.Match(file); ok {
+			return result, true
+		}
+	}
+
+	return nil, false
+}
+
+// Sources returns every mapping whose source matches file, regardless of their properties
+// i.e. all mappings that could have published file at some point
+func (c *Config) Sources(file string) []*Mapping {
+	result := make([]*Mapping, 0)
+	for _, mapping := range c.Mappings {
+		if matched, ok := mapping.MatchSource(file); ok {
+			result = append(result, matched)
+		}
+	}
+	return result
+}
+
+// match is Match for the logseq repository at root, hidden pages of the graph never match
+func (m *Mapping) match(root, file string) (*Mapping, bool) {
+	result, ok := m.MatchSource(file)
+	if !ok || m.options().Graph().IsHidden(file) {
+		return nil, false
+	}
+	if m.Property == "" {
+		return result, true
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, false
+	}
+
+	if !hasProperty(logseq.Parse(string(content)).Properties, m.Property) {
+		return nil, false
+	}
+	return result, true
+}
+
+// hasProperty reports whether properties contain the given key:: value property, a property without a value
+// matches every value but false, lists of values and page references like [[blog]] are supported
+func hasProperty(properties logseq.Properties, property string) bool {
+	key, want, _ := strings.Cut(property, "::")
+	want = strings.TrimSpace(want)
+
+	value, ok := properties.Get(strings.TrimSpace(key))
+	if !ok {
+		return false
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.Trim(strings.TrimSpace(item), "#[]")
+		if want == "" && item != "" && !strings.EqualFold(item, "false") {
+			return true
+		}
+		if want != "" && strings.EqualFold(item, want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches reports whether file matches the source pattern, namespaces are compared case insensitively
+// like logseq compares page names, a * only matches a single level of the namespace
+func (m *Mapping) matches(file string) bool {
+	if !m.IsNamespace() {
+		ok, _ := path.Match(m.Source, file)
+		return ok
+	}
+
+	if path.Dir(file) != "pages" || path.Ext(file) != ".md" {
+		return false
+	}
+
+	ok, _ := path.Match(strings.ToLower(m.Source), strings.ToLower(m.pageName(file)))
+	return ok
+}
+
+// pageName returns the name of the page stored in file, like logseq (and the recursion and link rewriting) it prefers
+// the title:: property of the page over the name encoded in its filename
+func (m *Mapping) pageName(file string) string {
+	return logseq.ResolvePageName(filepath.Join(m.options().LogseqRepositoryPath, filepath.FromSlash(file)), m.options().Graph().FileNameFormat)
+}
+
+// options returns the options of the mapping, which are only set once the config is loaded
+func (m *Mapping) options() *Options {
+	if m.Options == nil {
+		return &Options{}
+	}
+	return m.Options
+}
+
+// targetData describes file for the target template
+func (m *Mapping) targetData(file string) TargetData {
+	name := m.pageName(file)
+	data := TargetData{Name: name, Title: name, File: strings.TrimSuffix(path.Base(file), path.Ext(file))}
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		data.Namespace = name[:i]
+		data.Title = name[i+1:]
+	}
+	data.Slug = Slugify(m.options().Slug, data.Title)
+
+	if m.Journal != nil {
+		if date, ok := m.JournalDate(file); ok {
+			data.Date = date.Format(time.DateOnly)
+		}
+	}
+
+	return data
+}
+
+// executeTarget executes the target template tmpl of the mapping of source
+func executeTarget(source, tmpl string, data TargetData) (string, error) {
+	t, err := template.New(source).Funcs(targetFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var target strings.Builder
+	err = t.Execute(&target, data)
+	if err != nil {
+		return "", err
+	}
+
+	return target.String(), nil
+}
+
+// validateProperty checks the property a mapping publishes pages by
+func (m *Mapping) validateProperty() error {
+	key, _, _ := strings.Cut(m.Property, "::")
+	if strings.TrimSpace(key) == "" {
+		return errPropertyKey
+	}
+	return nil
+}
+
+// validatePattern checks the source pattern and the target template of the mapping
+func (m *Mapping) validatePattern() error {
+	pattern := m.Source
+	if m.IsNamespace() {
+		pattern = strings.ToLower(pattern)
+	}
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(m.Target, "{{") {
+		return errPatternTarget
+	}
+
+	_, err = executeTarget(m.Source, m.Target, m.targetData("pages/validate.md"))
+	return err
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestMatch(t *testing.T) {
+	cfg := &config.Config{Mappings: []*config.Mapping{
+		{Source: "pages/books___*.md", Target: "content/books/{{ .Slug }}.md"},
+		{Source: "pages/books___Dune.md", Target: "content/dune.md"},
+		{Source: "Notes/*", Target: "content/{{ slug .Namespace }}/{{ .Slug }}.md"},
+		{Source: "pages/foo.md", Target: "content/foo.md"},
+	}}
+
+	tests := []struct {
+		file   string
+		target string
+	}{
+		{file: "pages/foo.md", target: "content/foo.md"},
+		{file: "pages/books___Children of Time.md", target: "content/books/children-of-time.md"},
+		{file: "pages/books___Dune.md", target: "content/dune.md"},
+		{file: "pages/notes___Go Generics.md", target: "content/notes/go-generics.md"},
+		{file: "pages/notes___go___deep.md"},
+		{file: "pages/notes.md"},
+		{file: "journals/2024_06_12.md"},
+	}
+
+	for _, tt := range tests {
+		mapping, ok := cfg.Match(tt.file)
+		if ok != (tt.target != "") {
+			t.Errorf("%s: unexpected match %v", tt.file, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if mapping.Source != tt.file || mapping.Target != tt.target {
+			t.Errorf("%s: got %s -> %s, want %s", tt.file, mapping.Source, mapping.Target, tt.target)
+		}
+	}
+
+	if cfg.Mappings[0].Source != "pages/books___*.md" || cfg.Mappings[0].Target != "content/books/{{ .Slug }}.md" {
+		t.Errorf("the pattern mapping was changed: %+v", cfg.Mappings[0])
+	}
+}
+
+func TestExpand(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"pages/books___Dune.md": "- page\n",
+		"pages/books.md":        "- page\n",
+		"pages/other.md":        "- page\n",
+		// the filename of a legacy page can't hold the namespace, logseq names it after its title
+		"pages/Arrakis.md": "title:: books/Arrakis Rising\n\n- page\n",
+	})
+
+	m := &config.Mapping{Source: "books/*", Target: "content/books/{{ .Slug }}.md", Options: &config.Options{LogseqRepositoryPath: root}}
+	mapped, err := m.Expand(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mapped) != 2 || mapped["pages/books___Dune.md"] != "content/books/dune.md" || mapped["pages/Arrakis.md"] != "content/books/arrakis-rising.md" {
+		t.Errorf("unexpected pages %v", mapped)
+	}
+}
+
+func TestJournalDate(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+		want   string
+	}{
+		{file: "journals/2024_06_12.md", want: "2024-06-12"},
+		{format: "yyyy-MM-dd", file: "journals/2024-06-12.md", want: "2024-06-12"},
+		{format: "MMM d, yyyy", file: "journals/Jun 2, 2024.md", want: "2024-06-02"},
+		{file: "journals/notes.md"},
+	}
+
+	for _, tt := range tests {
+		j := &config.Journal{FileNameFormat: tt.format}
+		date, ok := j.Date(tt.file, logseq.DefaultJournalFileNameFormat)
+		if ok != (tt.want != "") || (ok && date.Format("2006-01-02") != tt.want) {
+			t.Errorf("%s with format %q: got %v %v, want %q", tt.file, tt.format, date, ok, tt.want)
+		}
+	}
+}
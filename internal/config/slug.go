@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gosimple/slug"
+)
+
+// Slugify turns a page name into a part of an url according to the slug strategy of a mapping
+// namespaces are separated by - like every other word
+func Slugify(strategy, name string) string {
+	if strategy == "" || strategy == SlugTransliterate {
+		return slug.Make(name)
+	}
+
+	var result strings.Builder
+	dash := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			// everything else separates words
+			dash = result.Len() > 0
+			continue
+		}
+
+		if dash {
+			result.WriteByte('-')
+			dash = false
+		}
+
+		if strategy == SlugLower {
+			r = unicode.ToLower(r)
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
@@ -0,0 +1,24 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     string
+	}{
+		{strategy: "", want: "books-uber-go"},
+		{strategy: config.SlugLower, want: "books-über-go"},
+		{strategy: config.SlugPreserve, want: "books-Über-Go"},
+	}
+
+	for _, tt := range tests {
+		if got := config.Slugify(tt.strategy, "books/Über Go!"); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.strategy, got, tt.want)
+		}
+	}
+}
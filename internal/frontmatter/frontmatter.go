@@ -0,0 +1,65 @@
+package frontmatter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// FormatTOML writes the front matter as toml between `+++` lines (default)
+	FormatTOML = "toml"
+	// FormatYAML writes the front matter as yaml between `---` lines
+	FormatYAML = "yaml"
+	// FormatJSON writes the front matter as a json object
+	FormatJSON = "json"
+)
+
+var (
+	errUnknownFormat = errors.New("unknown front matter format, use either 'toml', 'yaml' or 'json'")
+)
+
+// Encode returns the front matter document of values in the given format, including its delimiters
+// keys are sorted, so the same values always result in the same document
+func Encode(format string, values map[string]any) (string, error) {
+	switch format {
+	case FormatTOML, "":
+		document, err := encodeTOML(values)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("+++\n%s+++\n", document), nil
+
+	case FormatYAML:
+		if len(values) == 0 {
+			return "---\n---\n", nil
+		}
+
+		document, err := yaml.Marshal(values)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("---\n%s---\n", document), nil
+
+	case FormatJSON:
+		if values == nil {
+			values = map[string]any{}
+		}
+
+		// encoding/json sorts the keys of maps
+		document, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s\n", document), nil
+	}
+
+	return "", errUnknownFormat
+}
+
+// IsValidFormat reports whether format is a known front matter format, an empty format defaults to toml
+func IsValidFormat(format string) bool {
+	return format == "" || format == FormatTOML || format == FormatYAML || format == FormatJSON
+}
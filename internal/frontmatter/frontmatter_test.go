@@ -0,0 +1,119 @@
+package frontmatter_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lakrizz/logsync/internal/frontmatter"
+)
+
+func TestEncode(t *testing.T) {
+	// values are read from the json config, so numbers are floats
+	values := map[string]any{}
+	err := json.Unmarshal([]byte(`{
+		"title": "It's a \"title\"",
+		"draft": false,
+		"weight": 10,
+		"ratio": 1.5,
+		"tags": ["go", "hugo"],
+		"params": {"author": "me", "toc": true},
+		"menu": null
+	}`), &values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["date"] = time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: frontmatter.FormatTOML,
+			want: `+++
+date = 2024-06-12T10:00:00Z
+draft = false
+ratio = 1.5
+tags = ["go", "hugo"]
+title = "It's a \"title\""
+weight = 10
+
+[params]
+author = "me"
+toc = true
++++
+`,
+		},
+		{
+			format: frontmatter.FormatYAML,
+			want: `---
+date: 2024-06-12T10:00:00Z
+draft: false
+menu: null
+params:
+  author: me
+  toc: true
+ratio: 1.5
+tags:
+- go
+- hugo
+title: It's a "title"
+weight: 10
+---
+`,
+		},
+		{
+			format: frontmatter.FormatJSON,
+			want: `{
+  "date": "2024-06-12T10:00:00Z",
+  "draft": false,
+  "menu": null,
+  "params": {
+    "author": "me",
+    "toc": true
+  },
+  "ratio": 1.5,
+  "tags": [
+    "go",
+    "hugo"
+  ],
+  "title": "It's a \"title\"",
+  "weight": 10
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := frontmatter.Encode(tt.format, values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTOMLStrings(t *testing.T) {
+	got, err := frontmatter.Encode(frontmatter.FormatTOML, map[string]any{"a key": "line\nbreak\ttab\\"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "+++\n\"a key\" = \"line\\nbreak\\ttab\\\\\"\n+++\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	_, err := frontmatter.Encode("xml", map[string]any{})
+	if err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
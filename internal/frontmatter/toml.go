@@ -0,0 +1,206 @@
+package frontmatter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// encodeTOML encodes values as a toml document, nested maps become tables
+func encodeTOML(values map[string]any) (string, error) {
+	sb := &strings.Builder{}
+	err := encodeTable(sb, nil, reflect.ValueOf(values))
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// encodeTable writes all values of table, tables have to come after all other values, as every
+// key that follows a table header belongs to that table
+func encodeTable(sb *strings.Builder, path []string, table reflect.Value) error {
+	keys := sortedKeys(table)
+
+	tables := make([]reflect.Value, 0)
+	for _, k := range keys {
+		key := fmt.Sprint(k.Interface())
+		value := indirect(table.MapIndex(k))
+		if !value.IsValid() {
+			// toml has no null, so the key is omitted
+			continue
+		}
+
+		if value.Kind() == reflect.Map {
+			tables = append(tables, k)
+			continue
+		}
+
+		encoded, err := encodeValue(value)
+		if err != nil {
+			return fmt.Errorf("cannot encode %s: %w", strings.Join(append(path, key), "."), err)
+		}
+		fmt.Fprintf(sb, "%s = %s\n", encodeKey(key), encoded)
+	}
+
+	for _, k := range tables {
+		tablePath := append(slices.Clone(path), fmt.Sprint(k.Interface()))
+
+		header := make([]string, 0, len(tablePath))
+		for _, k := range tablePath {
+			header = append(header, encodeKey(k))
+		}
+
+		fmt.Fprintf(sb, "\n[%s]\n", strings.Join(header, "."))
+		err := encodeTable(sb, tablePath, indirect(table.MapIndex(k)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeValue(value reflect.Value) (string, error) {
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return encodeString(value.String()), nil
+
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat(value.Float()), nil
+
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item := indirect(value.Index(i))
+			if !item.IsValid() {
+				continue
+			}
+
+			encoded, err := encodeValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, encoded)
+		}
+		return fmt.Sprintf("[%s]", strings.Join(items, ", ")), nil
+
+	case reflect.Map:
+		// maps within arrays are written as inline tables
+		items := make([]string, 0, value.Len())
+		for _, key := range sortedKeys(value) {
+			item := indirect(value.MapIndex(key))
+			if !item.IsValid() {
+				continue
+			}
+
+			encoded, err := encodeValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, fmt.Sprintf("%s = %s", encodeKey(fmt.Sprint(key.Interface())), encoded))
+		}
+		return fmt.Sprintf("{ %s }", strings.Join(items, ", ")), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", value.Type())
+}
+
+// encodeFloat writes integral numbers without a fraction, json numbers from the config are always floats
+func encodeFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case f == math.Trunc(f) && math.Abs(f) < 1e15:
+		return strconv.FormatFloat(f, 'f', 0, 64)
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func encodeKey(key string) string {
+	if bareKeyRegex.MatchString(key) {
+		return key
+	}
+	return encodeString(key)
+}
+
+// encodeString writes s as a toml basic string
+func encodeString(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// sortedKeys returns the keys of m sorted by their string representation
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+	return keys
+}
+
+// indirect unwraps interfaces and pointers, nil values become invalid
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
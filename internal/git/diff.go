@@ -0,0 +1,125 @@
+package git
+
+import (
+	"errors"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+)
+
+var (
+	ErrUnknownRevision = errors.New("revision is not part of the local history")
+)
+
+// Change describes a file that differs between two revisions
+// From is empty for added files, To is empty for removed files and both differ for renamed files
+type Change struct {
+	From string
+	To   string
+}
+
+// Diff returns all files that changed between the trees of the two given commit hashes
+// deleted and added files with identical content are reported as a single rename
+func Diff(repo *git.Repository, from, to string) ([]Change, error) {
+	fromTree, err := tree(repo, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toTree, err := tree(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Change, 0, len(changes))
+	deleted := make(map[plumbing.Hash]int) // blob hash -> index in result
+	inserted := make([]*object.Change, 0)
+
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+
+		switch action {
+		case merkletrie.Insert:
+			inserted = append(inserted, change)
+		case merkletrie.Delete:
+			deleted[change.From.TreeEntry.Hash] = len(result)
+			result = append(result, Change{From: change.From.Name})
+		case merkletrie.Modify:
+			result = append(result, Change{From: change.From.Name, To: change.To.Name})
+		}
+	}
+
+	for _, change := range inserted {
+		if i, ok := deleted[change.To.TreeEntry.Hash]; ok {
+			delete(deleted, change.To.TreeEntry.Hash)
+			result[i].To = change.To.Name
+			continue
+		}
+		result = append(result, Change{To: change.To.Name})
+	}
+
+	return result, nil
+}
+
+// Files lists all files of the tree of the given revision
+func Files(repo *git.Repository, revision string) ([]string, error) {
+	t, err := tree(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	err = t.Files().ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Head returns the hash of the commit HEAD points to
+func Head(repo *git.Repository) (string, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Hash().String(), nil
+}
+
+// CurrentBranch returns the full name of the branch HEAD points to, e.g., refs/heads/main
+func CurrentBranch(repo *git.Repository) (string, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Name().String(), nil
+}
+
+func tree(repo *git.Repository, revision string) (*object.Tree, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, errors.Join(ErrUnknownRevision, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, errors.Join(ErrUnknownRevision, err)
+	}
+
+	return commit.Tree()
+}
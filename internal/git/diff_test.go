@@ -0,0 +1,67 @@
+package git_test
+
+import (
+	"slices"
+	"testing"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/git"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestDiff(t *testing.T) {
+	repo, err := gogit.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := testutil.CommitFiles(t, repo, map[string]string{
+		"pages/keep.md":   "- unchanged",
+		"pages/edit.md":   "- old",
+		"pages/remove.md": "- bye",
+		"pages/old.md":    "- renamed content",
+	})
+
+	after := testutil.CommitFiles(t, repo, map[string]string{
+		"pages/edit.md":   "- new",
+		"pages/remove.md": "",
+		"pages/old.md":    "",
+		"pages/new.md":    "- renamed content",
+		"pages/added.md":  "- hello",
+	})
+
+	changes, err := git.Diff(repo, before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []git.Change{
+		{From: "pages/edit.md", To: "pages/edit.md"},
+		{From: "pages/remove.md"},
+		{From: "pages/old.md", To: "pages/new.md"},
+		{To: "pages/added.md"},
+	} {
+		if !slices.Contains(changes, want) {
+			t.Errorf("missing change %+v in %+v", want, changes)
+		}
+	}
+
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %+v", changes)
+	}
+
+	_, err = git.Diff(repo, "0000000000000000000000000000000000000000", after)
+	if err == nil {
+		t.Fatal("expected an error for an unknown revision")
+	}
+
+	files, err := git.Files(repo, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(files)
+	if !slices.Equal(files, []string{"pages/added.md", "pages/edit.md", "pages/keep.md", "pages/new.md"}) {
+		t.Fatalf("unexpected files %v", files)
+	}
+}
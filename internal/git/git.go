@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 )
 
@@ -41,8 +44,12 @@ func Open(directory string) (*git.Repository, error) {
 	return git.PlainOpenWithOptions(directory, &git.PlainOpenOptions{})
 }
 
-// getSSHAgentAuth
-func sshPrivateKeyAuth(ssh_private_key_file, ssh_private_key_password string) (*ssh.PublicKeys, error) {
+// sshPrivateKeyAuth loads the given private key, without a key no authentication is used (e.g., for local repositories)
+func sshPrivateKeyAuth(ssh_private_key_file, ssh_private_key_password string) (transport.AuthMethod, error) {
+	if ssh_private_key_file == "" {
+		return nil, nil
+	}
+
 	_, err := os.Stat(ssh_private_key_file)
 	if err != nil {
 		return nil, err
@@ -81,6 +88,39 @@ func Pull(repo *git.Repository, ssh_key_path, ssh_key_password string) error {
 	return nil
 }
 
+// Fetch updates the remote-tracking branches of the repository, other than Pull it leaves the worktree alone
+func Fetch(repo *git.Repository, ssh_key_path, ssh_key_password string) error {
+	auth, err := sshPrivateKeyAuth(ssh_key_path, ssh_key_password)
+	if err != nil {
+		return err
+	}
+
+	err = repo.Fetch(&git.FetchOptions{RemoteName: "origin", Auth: auth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	return nil
+}
+
+// UpstreamTree returns the tree of the fetched origin branch of the checked out branch
+// without a remote-tracking branch, the tree of HEAD is returned
+func UpstreamTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		ref = head
+	} else if err != nil {
+		return nil, err
+	}
+
+	return tree(repo, ref.Hash().String())
+}
+
 func Reset(repo *git.Repository) error {
 	worktree, err := repo.Worktree()
 	if err != nil {
@@ -101,7 +141,7 @@ func Push(repo *git.Repository, ssh_key_path, ssh_key_password string) error {
 	}
 
 	err = repo.Push(&git.PushOptions{RemoteName: "origin", Auth: auth})
-	if err != nil {
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
 		return err
 	}
 
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Dates are the author dates of the first and the latest commit that touched a file
+type Dates struct {
+	Created  time.Time
+	Modified time.Time
+}
+
+// History returns the Dates of every file of HEAD's history, keyed by its path relative to the root of the repository
+// the history is walked only once, every commit is compared to its first parent
+func History(repo *git.Repository) (map[string]Dates, error) {
+	commits, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	result := make(map[string]Dates)
+	err = commits.ForEach(func(c *object.Commit) error {
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+
+		// the root commit is compared to an empty tree, i.e. it touches all of its files
+		var parentTree *object.Tree
+		if c.NumParents() > 0 {
+			parent, err := c.Parent(0)
+			if err != nil {
+				return err
+			}
+
+			parentTree, err = parent.Tree()
+			if err != nil {
+				return err
+			}
+		}
+
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+
+		when := c.Author.When
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+
+			dates, ok := result[name]
+			if !ok || when.Before(dates.Created) {
+				dates.Created = when
+			}
+			if !ok || when.After(dates.Modified) {
+				dates.Modified = when
+			}
+			result[name] = dates
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
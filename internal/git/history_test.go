@@ -0,0 +1,60 @@
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/git"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestHistory(t *testing.T) {
+	repo, err := gogit.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+	modified := created.Add(48 * time.Hour)
+
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/foo.md": "- first", "pages/baz.md": "- once"}, created)
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/bar.md": "- unrelated"}, created.Add(time.Hour))
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/foo.md": "- second"}, modified)
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/bar.md": "- unrelated again"}, modified.Add(time.Hour))
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/gone.md": "- short lived"}, modified.Add(2*time.Hour))
+	testutil.CommitFilesAt(t, repo, map[string]string{"pages/gone.md": ""}, modified.Add(3*time.Hour))
+
+	history, err := git.History(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file     string
+		created  time.Time
+		modified time.Time
+	}{
+		{file: "pages/foo.md", created: created, modified: modified},
+		{file: "pages/baz.md", created: created, modified: created},
+		{file: "pages/bar.md", created: created.Add(time.Hour), modified: modified.Add(time.Hour)},
+		{file: "pages/gone.md", created: modified.Add(2 * time.Hour), modified: modified.Add(3 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		dates, ok := history[tt.file]
+		if !ok {
+			t.Errorf("%s: no history", tt.file)
+			continue
+		}
+
+		if !dates.Created.Equal(tt.created) || !dates.Modified.Equal(tt.modified) {
+			t.Errorf("%s: got %v - %v, want %v - %v", tt.file, dates.Created, dates.Modified, tt.created, tt.modified)
+		}
+	}
+
+	if _, ok := history["pages/missing.md"]; ok {
+		t.Error("file that was never committed has a history")
+	}
+}
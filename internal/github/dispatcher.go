@@ -0,0 +1,160 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	// EventHeader carries the name of the event that triggered the delivery
+	EventHeader = "X-GitHub-Event"
+
+	EventPing       = "ping"
+	EventPush       = "push"
+	EventCreate     = "create"
+	EventDelete     = "delete"
+	EventRepository = "repository"
+)
+
+var (
+	errMissingEvent = errors.New("webhook request has no event header")
+)
+
+// Dispatcher is an http.Handler that verifies GitHub webhook deliveries, decodes them based on
+// their X-GitHub-Event header and hands them to the handler registered for that event
+// handlers should return quickly (e.g., by queueing work), deliveries they accept are answered with 202 Accepted
+type Dispatcher struct {
+	secret   string
+	log      *slog.Logger
+	handlers map[string]func(delivery string, payload []byte) error
+}
+
+// NewDispatcher creates a dispatcher that only accepts deliveries signed with the given secret
+func NewDispatcher(secret string, log *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		secret:   secret,
+		log:      log,
+		handlers: make(map[string]func(string, []byte) error),
+	}
+}
+
+// OnPing registers the handler for ping events, which GitHub sends after a hook has been created
+func (d *Dispatcher) OnPing(fn func(delivery string, payload *PingPayload) error) {
+	d.handlers[EventPing] = decodeInto(fn)
+}
+
+// OnPush registers the handler for push events
+func (d *Dispatcher) OnPush(fn func(delivery string, payload *PushPayload) error) {
+	d.handlers[EventPush] = decodeInto(fn)
+}
+
+// OnCreate registers the handler for create events (new branches or tags)
+func (d *Dispatcher) OnCreate(fn func(delivery string, payload *CreatePayload) error) {
+	d.handlers[EventCreate] = decodeInto(fn)
+}
+
+// OnDelete registers the handler for delete events (deleted branches or tags)
+func (d *Dispatcher) OnDelete(fn func(delivery string, payload *DeletePayload) error) {
+	d.handlers[EventDelete] = decodeInto(fn)
+}
+
+// OnRepository registers the handler for repository events (e.g., renamed or archived repositories)
+func (d *Dispatcher) OnRepository(fn func(delivery string, payload *RepositoryPayload) error) {
+	d.handlers[EventRepository] = decodeInto(fn)
+}
+
+// Events returns the names of all events a handler is registered for
+func (d *Dispatcher) Events() []string {
+	events := make([]string, 0, len(d.handlers))
+	for event := range d.handlers {
+		if event == EventPing {
+			// github sends pings regardless of the subscribed events
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	delivery := r.Header.Get(DeliveryHeader)
+	log := d.log.With("delivery", delivery)
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Error("error while reading github webhook request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = VerifySignature(d.secret, r.Header.Get(SignatureHeader), data)
+	if err != nil {
+		log.Warn("rejecting github webhook request", "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get(EventHeader)
+	if event == "" {
+		log.Warn("rejecting github webhook request", "error", errMissingEvent)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	handler, ok := d.handlers[event]
+	if !ok {
+		log.Info("ignoring github webhook event", "event", event)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	log.Info("received github webhook event", "event", event)
+	err = handler(delivery, data)
+	if err != nil {
+		var decodeErr *decodeError
+		if errors.As(err, &decodeErr) {
+			log.Error("error decoding github webhook payload", "event", event, "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		log.Error("error handling github webhook event", "event", event, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// decodeError marks payloads that could not be unmarshalled into their model
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+func decodeInto[T any](fn func(string, *T) error) func(string, []byte) error {
+	return func(delivery string, data []byte) error {
+		payload := new(T)
+		err := json.Unmarshal(data, payload)
+		if err != nil {
+			return &decodeError{err: err}
+		}
+
+		return fn(delivery, payload)
+	}
+}
@@ -0,0 +1,67 @@
+package github_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/github"
+)
+
+func TestDispatcher(t *testing.T) {
+	const secret = "s3cr3t"
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var pushed *github.PushPayload
+	d := github.NewDispatcher(secret, log)
+	d.OnPing(func(string, *github.PingPayload) error { return nil })
+	d.OnPush(func(_ string, payload *github.PushPayload) error {
+		pushed = payload
+		if payload.Ref == "refs/heads/broken" {
+			return errors.New("sync failed")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		event    string
+		body     string
+		unsigned bool
+		want     int
+	}{
+		{name: "ping", event: "ping", body: `{"hook_id":1}`, want: http.StatusAccepted},
+		{name: "push", event: "push", body: `{"ref":"refs/heads/main"}`, want: http.StatusAccepted},
+		{name: "push with failing handler", event: "push", body: `{"ref":"refs/heads/broken"}`, want: http.StatusInternalServerError},
+		{name: "malformed push", event: "push", body: `{"ref":`, want: http.StatusBadRequest},
+		{name: "unhandled event", event: "issues", body: `{}`, want: http.StatusNoContent},
+		{name: "missing event", event: "", body: `{}`, want: http.StatusBadRequest},
+		{name: "unsigned", event: "push", body: `{}`, unsigned: true, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			req.Header.Set(github.EventHeader, tt.event)
+			req.Header.Set(github.DeliveryHeader, "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+			if !tt.unsigned {
+				req.Header.Set(github.SignatureHeader, sign(secret, []byte(tt.body)))
+			}
+
+			rec := httptest.NewRecorder()
+			d.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	if pushed == nil || pushed.Ref != "refs/heads/broken" {
+		t.Fatalf("push handler did not receive the decoded payload: %+v", pushed)
+	}
+}
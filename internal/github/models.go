@@ -306,3 +306,61 @@ type PingPayload struct {
 		SiteAdmin         bool   `json:"site_admin"`
 	} `json:"sender"`
 }
+
+// CreatePayload contains the information for GitHub's create event, which is sent for new branches and tags
+type CreatePayload struct {
+	Ref          string     `json:"ref"`
+	RefType      string     `json:"ref_type"`
+	MasterBranch string     `json:"master_branch"`
+	Description  *string    `json:"description"`
+	PusherType   string     `json:"pusher_type"`
+	Repository   Repository `json:"repository"`
+	Sender       User       `json:"sender"`
+}
+
+// DeletePayload contains the information for GitHub's delete event, which is sent for deleted branches and tags
+type DeletePayload struct {
+	Ref        string     `json:"ref"`
+	RefType    string     `json:"ref_type"`
+	PusherType string     `json:"pusher_type"`
+	Repository Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+}
+
+// RepositoryPayload contains the information for GitHub's repository event, e.g. when the repository is renamed or archived
+type RepositoryPayload struct {
+	Action  string `json:"action"`
+	Changes struct {
+		Repository struct {
+			Name struct {
+				From string `json:"from"`
+			} `json:"name"`
+		} `json:"repository"`
+	} `json:"changes"`
+	Repository Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+}
+
+// Repository contains the subset of repository information logsync makes use of
+type Repository struct {
+	ID            int64  `json:"id"`
+	NodeID        string `json:"node_id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Private       bool   `json:"private"`
+	HTMLURL       string `json:"html_url"`
+	Archived      bool   `json:"archived"`
+	GitURL        string `json:"git_url"`
+	SSHURL        string `json:"ssh_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// User contains the subset of user information logsync makes use of
+type User struct {
+	Login   string `json:"login"`
+	ID      int64  `json:"id"`
+	NodeID  string `json:"node_id"`
+	HTMLURL string `json:"html_url"`
+	Type    string `json:"type"`
+}
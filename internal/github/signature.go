@@ -0,0 +1,67 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the payload, see https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+	SignatureHeader = "X-Hub-Signature-256"
+	// DeliveryHeader carries the unique id of a webhook delivery
+	DeliveryHeader = "X-GitHub-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	errMissingSignature   = errors.New("webhook request is not signed")
+	errMalformedSignature = errors.New("webhook signature is malformed")
+	errInvalidSignature   = errors.New("webhook signature does not match payload")
+	errEmptySecret        = errors.New("webhook secret is empty")
+)
+
+// VerifySignature checks the value of the X-Hub-Signature-256 header against the payload
+// the comparison is done in constant time
+func VerifySignature(secret, signature string, payload []byte) error {
+	if secret == "" {
+		return errEmptySecret
+	}
+
+	if signature == "" {
+		return errMissingSignature
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errMalformedSignature
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return errMalformedSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// GenerateSecret returns a random hex encoded secret that can be used for a webhook
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,43 @@
+package github_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/github"
+)
+
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", secret: "s3cr3t", signature: sign("s3cr3t", payload)},
+		{name: "wrong secret", secret: "s3cr3t", signature: sign("other", payload), wantErr: true},
+		{name: "unsigned", secret: "s3cr3t", signature: "", wantErr: true},
+		{name: "sha1 signature", secret: "s3cr3t", signature: "sha1=abcdef", wantErr: true},
+		{name: "no hex", secret: "s3cr3t", signature: "sha256=zz", wantErr: true},
+		{name: "empty secret", secret: "", signature: sign("", payload), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := github.VerifySignature(tt.secret, tt.signature, payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
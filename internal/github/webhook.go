@@ -4,51 +4,155 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"regexp"
 
 	"github.com/google/go-github/v60/github"
 )
 
-// SetWebhook function deletes all webhooks and adds the given url as the sole webhook
-func SetWebhook(ctx context.Context, github_token, target_url, logseq_repository_url string) error {
+// hookMarker is added as a query parameter to the url of every webhook logsync creates
+// this way we can tell our hooks apart from other integrations on the same repository
+const hookMarker = "logsync"
+
+// SetWebhook function creates the logsync webhook for the given url or updates an existing one in place
+// all deliveries are signed with the given secret and only contain the given events
+// webhooks that were not created by logsync are never touched
+func SetWebhook(ctx context.Context, github_token, target_url, logseq_repository_url, secret string, events []string) (int64, error) {
 	client := github.NewClient(nil).WithAuthToken(github_token)
+	return setWebhook(ctx, client, target_url, logseq_repository_url, secret, events)
+}
 
+// RemoveWebhook deletes all webhooks created by logsync from the given repository
+func RemoveWebhook(ctx context.Context, github_token, logseq_repository_url string) error {
+	client := github.NewClient(nil).WithAuthToken(github_token)
+	return removeWebhook(ctx, client, logseq_repository_url)
+}
+
+func setWebhook(ctx context.Context, client *github.Client, target_url, logseq_repository_url, secret string, events []string) (int64, error) {
 	github_user, github_repository, err := extractUsernameAndRepo(logseq_repository_url)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	repos, _, err := client.Repositories.ListHooks(ctx, github_user, github_repository, &github.ListOptions{})
+	marked_url, err := markURL(target_url)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	for _, v := range repos {
-		_, err := client.Repositories.DeleteHook(ctx, github_user, github_repository, *v.ID)
-		if err != nil {
-			return err
-		}
+	hooks, err := listLogsyncHooks(ctx, client, github_user, github_repository)
+	if err != nil {
+		return 0, err
 	}
 
 	hook_config := &github.Hook{
 		Name: github.String("web"),
 		Config: &github.HookConfig{
 			ContentType: github.String("json"),
-			URL:         github.String(target_url),
+			URL:         github.String(marked_url),
+			Secret:      github.String(secret),
 		},
-		Events: []string{"push"},
+		Events: events,
 		Active: github.Bool(true),
 	}
 
-	hook, _, err := client.Repositories.CreateHook(ctx, github_user, github_repository, hook_config)
+	if len(hooks) == 0 {
+		hook, _, err := client.Repositories.CreateHook(ctx, github_user, github_repository, hook_config)
+		if err != nil {
+			return 0, err
+		}
+
+		slog.Debug("new hook created", "id", hook.GetID())
+		return hook.GetID(), nil
+	}
+
+	// update our first hook in place, all others are leftovers of earlier runs
+	hook, _, err := client.Repositories.EditHook(ctx, github_user, github_repository, hooks[0].GetID(), hook_config)
+	if err != nil {
+		return 0, err
+	}
+	slog.Debug("existing hook updated", "id", hook.GetID())
+
+	for _, stale := range hooks[1:] {
+		_, err := client.Repositories.DeleteHook(ctx, github_user, github_repository, stale.GetID())
+		if err != nil {
+			return 0, err
+		}
+		slog.Debug("stale hook removed", "id", stale.GetID())
+	}
+
+	return hook.GetID(), nil
+}
+
+func removeWebhook(ctx context.Context, client *github.Client, logseq_repository_url string) error {
+	github_user, github_repository, err := extractUsernameAndRepo(logseq_repository_url)
+	if err != nil {
+		return err
+	}
+
+	hooks, err := listLogsyncHooks(ctx, client, github_user, github_repository)
 	if err != nil {
 		return err
 	}
 
-	slog.Debug("new hook created", "id", hook.ID)
+	for _, hook := range hooks {
+		_, err := client.Repositories.DeleteHook(ctx, github_user, github_repository, hook.GetID())
+		if err != nil {
+			return err
+		}
+		slog.Debug("hook removed", "id", hook.GetID())
+	}
 
 	return nil
+}
+
+// listLogsyncHooks walks all pages of the repository's hooks and returns those created by logsync
+func listLogsyncHooks(ctx context.Context, client *github.Client, github_user, github_repository string) ([]*github.Hook, error) {
+	result := make([]*github.Hook, 0)
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		hooks, resp, err := client.Repositories.ListHooks(ctx, github_user, github_repository, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hook := range hooks {
+			if isLogsyncHook(hook) {
+				result = append(result, hook)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func isLogsyncHook(hook *github.Hook) bool {
+	if hook.Config == nil || hook.Config.URL == nil {
+		return false
+	}
+
+	u, err := url.Parse(*hook.Config.URL)
+	if err != nil {
+		return false
+	}
+
+	return u.Query().Has(hookMarker)
+}
+
+func markURL(target_url string) (string, error) {
+	u, err := url.Parse(target_url)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set(hookMarker, "1")
+	u.RawQuery = query.Encode()
 
+	return u.String(), nil
 }
 
 // ExtractUsernameAndRepo extracts the username and repository name from a given URL.
@@ -60,7 +164,7 @@ func extractUsernameAndRepo(url string) (string, string, error) {
 	re := regexp.MustCompile(pattern)
 	// find matches
 	matches := re.FindStringSubmatch(url)
-	if len(matches) < 3 { 
+	if len(matches) < 3 {
 		return "", "", fmt.Errorf("no match found")
 	}
 
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// fakeHooks is a minimal stand-in for GitHub's repository hooks api, it serves one hook per page
+type fakeHooks struct {
+	hooks   map[int64]string // id -> url
+	deleted []int64
+	edited  []int64
+	created int
+}
+
+func (f *fakeHooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/repos/lakrizz/graph/hooks"
+	id := int64(0)
+	fmt.Sscanf(strings.TrimPrefix(r.URL.Path, prefix+"/"), "%d", &id)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		ids := make([]int64, 0, len(f.hooks))
+		for id := range f.hooks {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+
+		page := 1
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		if page < len(ids) {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next"`, r.Host, prefix, page+1))
+		}
+		json.NewEncoder(w).Encode([]*github.Hook{{ID: github.Int64(ids[page-1]), Config: &github.HookConfig{URL: github.String(f.hooks[ids[page-1]])}}})
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		f.created++
+		json.NewEncoder(w).Encode(&github.Hook{ID: github.Int64(100)})
+	case r.Method == http.MethodPatch:
+		f.edited = append(f.edited, id)
+		json.NewEncoder(w).Encode(&github.Hook{ID: github.Int64(id)})
+	case r.Method == http.MethodDelete:
+		f.deleted = append(f.deleted, id)
+		delete(f.hooks, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFakeClient(t *testing.T, fake *fakeHooks) *github.Client {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+	return client
+}
+
+func TestSetWebhookLeavesForeignHooksAlone(t *testing.T) {
+	fake := &fakeHooks{hooks: map[int64]string{
+		1: "https://ci.example.com/hook",
+		2: "https://abcd.ngrok.app?logsync=1",
+		3: "https://chat.example.com/hook",
+		4: "https://old.ngrok.app/?logsync=1",
+	}}
+	client := newFakeClient(t, fake)
+
+	id, err := setWebhook(context.Background(), client, "https://new.ngrok.app", "git@github.com:lakrizz/graph.git", "secret", []string{"push"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != 2 || !slices.Equal(fake.edited, []int64{2}) || fake.created != 0 {
+		t.Fatalf("expected hook 2 to be updated in place, got id=%d edited=%v created=%d", id, fake.edited, fake.created)
+	}
+
+	if !slices.Equal(fake.deleted, []int64{4}) {
+		t.Fatalf("expected only the stale logsync hook to be removed, got %v", fake.deleted)
+	}
+
+	err = removeWebhook(context.Background(), client, "git@github.com:lakrizz/graph.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fake.hooks[1]; !ok {
+		t.Fatal("foreign hook 1 was removed")
+	}
+	if _, ok := fake.hooks[3]; !ok {
+		t.Fatal("foreign hook 3 was removed")
+	}
+	if _, ok := fake.hooks[2]; ok {
+		t.Fatal("logsync hook 2 was not removed")
+	}
+}
+
+func TestSetWebhookCreatesHook(t *testing.T) {
+	fake := &fakeHooks{hooks: map[int64]string{1: "https://ci.example.com/hook"}}
+	client := newFakeClient(t, fake)
+
+	id, err := setWebhook(context.Background(), client, "https://new.ngrok.app", "https://github.com/lakrizz/graph.git", "secret", []string{"push"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != 100 || fake.created != 1 || len(fake.deleted) != 0 {
+		t.Fatalf("expected a new hook, got id=%d created=%d deleted=%v", id, fake.created, fake.deleted)
+	}
+}
@@ -0,0 +1,123 @@
+package hugo
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/index"
+
+	"github.com/lakrizz/logsync/internal/state"
+)
+
+// attachmentDirectory is where the include attachments option copies all attachments to
+const attachmentDirectory = "static"
+
+var (
+	attachmentRegex = regexp.MustCompile(`!\[[^\]]*\]\(/([^)/]+)\)`)
+)
+
+// attachmentsOf returns the names of all attachments the include attachments option linked in content
+func attachmentsOf(content string) []string {
+	result := make([]string, 0)
+	for _, match := range attachmentRegex.FindAllStringSubmatch(content, -1) {
+		result = append(result, match[1])
+	}
+	return result
+}
+
+// unusedAttachments returns the files of all given attachments that exist and are not used by any page that is still
+// published, these are the targets of the sync state (including the pages of recursions and the posts of journals)
+// that are neither removed nor rendered again, along with the pages rendered by this sync (target -> content)
+func unusedAttachments(attachments []string, published map[string]*state.Source, r *rendering, written map[string]string, read readFunc, log *slog.Logger) ([]string, error) {
+	result := make([]string, 0)
+	if len(attachments) == 0 {
+		return result, nil
+	}
+
+	inUse := make(map[string]bool)
+	for _, content := range written {
+		for _, name := range attachmentsOf(content) {
+			inUse[name] = true
+		}
+	}
+
+	for file, source := range published {
+		if _, changed := r.sources[file]; changed || source == nil {
+			continue
+		}
+
+		for _, target := range source.Targets {
+			if _, ok := written[target]; ok || slices.Contains(r.removed, target) {
+				continue
+			}
+
+			content, err := read(target)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range attachmentsOf(content) {
+				inUse[name] = true
+			}
+		}
+	}
+
+	for _, name := range attachments {
+		file := path.Join(attachmentDirectory, name)
+		if slices.Contains(result, file) {
+			continue
+		}
+		if inUse[name] {
+			log.Info("attachment is still in use, keeping it", "attachment", name)
+			continue
+		}
+
+		_, err := read(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, file)
+	}
+
+	return result, nil
+}
+
+// removeFile deletes a file from the worktree and the index, files that are not tracked are simply deleted
+func removeFile(worktree *git.Worktree, file string) error {
+	_, err := worktree.Remove(file)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return worktree.Filesystem.Remove(file)
+	}
+	return err
+}
+
+func readFile(fs billy.Filesystem, name string) (string, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sb := strings.Builder{}
+	_, err = io.Copy(&sb, f)
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,68 @@
+package hugo
+
+import (
+	"slices"
+)
+
+// ChangeSet collects the logseq files that need to be (re-)published or unpublished
+// changes have to be recorded in the order they happened, later changes to a file win
+type ChangeSet struct {
+	Modified []string          // added or modified files, these are (re-)published
+	Removed  []string          // removed files, their targets are deleted
+	Renamed  map[string]string // old name -> new name, the old name is part of Removed, the new one of Modified
+}
+
+func NewChangeSet() *ChangeSet {
+	return &ChangeSet{
+		Modified: make([]string, 0),
+		Removed:  make([]string, 0),
+		Renamed:  make(map[string]string),
+	}
+}
+
+// Modify marks a file as added or modified
+func (c *ChangeSet) Modify(file string) {
+	c.Removed = remove(c.Removed, file)
+	if !slices.Contains(c.Modified, file) {
+		c.Modified = append(c.Modified, file)
+	}
+}
+
+// Remove marks a file as removed
+func (c *ChangeSet) Remove(file string) {
+	c.Modified = remove(c.Modified, file)
+	if !slices.Contains(c.Removed, file) {
+		c.Removed = append(c.Removed, file)
+	}
+}
+
+// Rename marks a file as moved, the old file is unpublished and the new one published
+func (c *ChangeSet) Rename(from, to string) {
+	c.Remove(from)
+	c.Modify(to)
+	c.Renamed[from] = to
+}
+
+// Filter drops all files that don't satisfy keep, rename entries are kept as long as one side is kept
+func (c *ChangeSet) Filter(keep func(file string) bool) {
+	c.Modified = slices.DeleteFunc(c.Modified, func(file string) bool { return !keep(file) })
+	c.Removed = slices.DeleteFunc(c.Removed, func(file string) bool { return !keep(file) })
+	for from, to := range c.Renamed {
+		if !keep(from) && !keep(to) {
+			delete(c.Renamed, from)
+		}
+	}
+}
+
+// Files returns all files that are part of this change set
+func (c *ChangeSet) Files() []string {
+	return append(slices.Clone(c.Modified), c.Removed...)
+}
+
+func (c *ChangeSet) IsEmpty() bool {
+	return len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+func remove(files []string, file string) []string {
+	return slices.DeleteFunc(files, func(s string) bool { return s == file })
+}
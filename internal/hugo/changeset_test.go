@@ -0,0 +1,32 @@
+package hugo_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/hugo"
+)
+
+func TestChangeSet(t *testing.T) {
+	changes := hugo.NewChangeSet()
+	changes.Modify("pages/a.md")
+	changes.Remove("pages/b.md")
+	changes.Remove("pages/a.md") // later changes win
+	changes.Modify("pages/b.md")
+	changes.Rename("pages/c.md", "pages/d.md")
+	changes.Modify("pages/unmapped.md")
+
+	changes.Filter(func(file string) bool { return file != "pages/unmapped.md" })
+
+	if !slices.Equal(changes.Modified, []string{"pages/b.md", "pages/d.md"}) {
+		t.Fatalf("unexpected modified files: %v", changes.Modified)
+	}
+
+	if !slices.Equal(changes.Removed, []string{"pages/a.md", "pages/c.md"}) {
+		t.Fatalf("unexpected removed files: %v", changes.Removed)
+	}
+
+	if changes.Renamed["pages/c.md"] != "pages/d.md" {
+		t.Fatalf("rename was not recorded: %v", changes.Renamed)
+	}
+}
@@ -1,84 +1,207 @@
 package hugo
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 	"time"
 
-	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 
 	"github.com/lakrizz/logsync/internal/config"
-	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/state"
 )
 
-func HandleModifiedPages(files []string, cfg *config.Config, logseqRepository, hugoRepository *git.Repository, log *slog.Logger) error {
-	logseqWorktree, err := logseqRepository.Worktree()
+// Result describes what HandleModifiedPages changed in the hugo repository
+type Result struct {
+	Commit  string                   // hash of the logsync commit, empty if nothing was committed
+	Written map[string]string        // target -> sha256 of its new content
+	Removed []string                 // removed targets
+	Sources map[string]*state.Source // logseq file -> what it publishes now, nil for files that publish nothing anymore
+}
+
+// HandleModifiedPages publishes all modified files of the change set and deletes the targets of all removed ones
+// published is what every logseq file published during the previous syncs, the targets changed files published
+// back then but not anymore (e.g. posts that were removed from a journal) are deleted as well
+// everything, including the attachments the pages use, is committed as a single logsync commit to the hugo repository
+func HandleModifiedPages(changes *ChangeSet, cfg *config.Config, published map[string]*state.Source, logseqRepository, hugoRepository *git.Repository, log *slog.Logger) (*Result, error) {
+	result := &Result{Written: make(map[string]string), Removed: make([]string, 0), Sources: make(map[string]*state.Source)}
+
+	hugoWorktree, err := hugoRepository.Worktree()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	hugoWorktree, err := hugoRepository.Worktree()
+	r, err := render(changes, cfg, published, logseqRepository, func(name string) (string, error) { return readFile(hugoWorktree.Filesystem, name) }, log)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	result.Sources = r.sources
 
-	for _, file := range files {
-		i := slices.IndexFunc(cfg.Mappings, func(s *config.Mapping) bool {
-			return s.Source == file
-		})
-		if i == -1 {
-			continue
+	for _, target := range r.removed {
+		log.Info("removing target of removed page", "target", target)
+		err = removeFile(hugoWorktree, target)
+		if err != nil {
+			return nil, fmt.Errorf("cannot remove from worktree: %w", err)
 		}
+		result.Removed = append(result.Removed, target)
+	}
 
-		var sourceFile billy.File
-		var targetFile billy.File
-		var parsedPage *mapping.LogseqPage
-		target := cfg.Mappings[i]
-
-		sourceFile, err = logseqWorktree.Filesystem.Open(file)
+	for _, file := range r.removedAttachments {
+		log.Info("removing attachment of removed page", "attachment", file)
+		err = removeFile(hugoWorktree, file)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("cannot remove from worktree: %w", err)
 		}
+	}
 
-		targetFile, err = hugoWorktree.Filesystem.Create(target.Target)
+	for _, page := range r.pages {
+		log.Info("copying new hugo file", "target", page.Target)
+		err = page.Save(filepath.Join(hugoWorktree.Filesystem.Root(), page.Target))
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("cannot copy file: %w", err)
 		}
 
-		log.Info("parsing logseq file...", "filename", logseqWorktree.Filesystem.Root())
-		// here we need to convert the logseq pages to hugo pages
-		// by adding frontmatter, etc.
-		parsedPage, err = mapping.ParsePage(log, filepath.Join(logseqWorktree.Filesystem.Root(), sourceFile.Name()), target)
+		err = addFile(hugoWorktree, page.Target)
+		if err != nil {
+			return nil, err
+		}
+		result.Written[page.Target] = hashContent(page.ParsedContent)
+	}
 
+	// attachments are part of the same commit as the pages that use them
+	for _, attachment := range r.attachments {
+		log.Info("copying attachment", "attachment", attachment.name)
+		file := filepath.Join(hugoWorktree.Filesystem.Root(), attachment.name)
+		err = os.MkdirAll(filepath.Dir(file), 0777)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		log.Info("copying new hugo file")
-		err = parsedPage.Save(filepath.Join(hugoWorktree.Filesystem.Root(), targetFile.Name()))
+		err = os.WriteFile(file, attachment.content, 0777)
 		if err != nil {
-			return fmt.Errorf("cannot copy file: %w", err)
+			return nil, fmt.Errorf("cannot copy attachment: %w", err)
 		}
 
-		log.Info("adding file to index")
-		_, err = hugoWorktree.Add(targetFile.Name())
+		err = addFile(hugoWorktree, attachment.name)
 		if err != nil {
-			return fmt.Errorf("cannot add to worktree: %w", err)
+			return nil, err
 		}
 	}
 
+	if len(result.Written) == 0 && len(result.Removed) == 0 && len(r.attachments) == 0 && len(r.removedAttachments) == 0 {
+		log.Info("no mapped pages were changed, nothing to commit")
+		return result, nil
+	}
+
 	// now we need to create a commit and push import
-	commitMessage := fmt.Sprintf("logsync autocommit %v / files: %v", time.Now().Format(time.RFC3339), strings.Join(files, ","))
-	_, err = hugoWorktree.Commit(commitMessage, &git.CommitOptions{Author: &object.Signature{Name: cfg.Git.Username, Email: cfg.Git.Email, When: time.Now()}})
+	commitMessage := fmt.Sprintf("logsync autocommit %v / files: %v", time.Now().Format(time.RFC3339), strings.Join(changes.Files(), ","))
+	hash, err := hugoWorktree.Commit(commitMessage, &git.CommitOptions{Author: &object.Signature{Name: cfg.Git.Username, Email: cfg.Git.Email, When: time.Now()}})
 	if err != nil {
-		return errors.Join(errors.New("cannot commit"), err)
+		return nil, errors.Join(errors.New("cannot commit"), err)
+	}
+	result.Commit = hash.String()
+
+	return result, nil
+}
+
+// staleTargets returns the mappings of all targets that have to be removed: the targets of removed files and
+// the targets modified files are no longer published to, e.g. because they lost the property of their mapping
+// journals have no target of their own, their posts are removed by unpublishedTargets
+func staleTargets(changes *ChangeSet, cfg *config.Config) []*config.Mapping {
+	result := make([]*config.Mapping, 0)
+	for _, file := range changes.Removed {
+		for _, mapping := range cfg.Sources(file) {
+			if mapping.Journal == nil {
+				result = append(result, mapping)
+			}
+		}
 	}
 
+	for _, file := range changes.Modified {
+		current, published := cfg.Match(file)
+		for _, mapping := range cfg.Sources(file) {
+			if mapping.Journal == nil && (!published || mapping.Target != current.Target) {
+				result = append(result, mapping)
+			}
+		}
+	}
+
+	return result
+}
+
+// unpublishedTargets returns the mappings of all targets the changed files published during the previous syncs
+// but not anymore, according to sources, which is what they publish now, e.g. the posts that were removed from
+// a journal, targets that are still published by any other file are kept
+func unpublishedTargets(changes *ChangeSet, cfg *config.Config, published, sources map[string]*state.Source) []*config.Mapping {
+	current := make(map[string]bool)
+	for file, source := range published {
+		if _, changed := sources[file]; changed || source == nil {
+			continue
+		}
+		for _, target := range source.Targets {
+			current[target] = true
+		}
+	}
+	for _, source := range sources {
+		if source == nil {
+			continue
+		}
+		for _, target := range source.Targets {
+			current[target] = true
+		}
+	}
+
+	result := make([]*config.Mapping, 0)
+	for _, file := range changes.Files() {
+		previous, ok := published[file]
+		if !ok || previous == nil {
+			continue
+		}
+
+		for _, target := range previous.Targets {
+			if current[target] {
+				continue
+			}
+			current[target] = true // every target is only removed once
+
+			result = append(result, unpublishedMapping(cfg, file, target))
+		}
+	}
+
+	return result
+}
+
+// unpublishedMapping returns the mapping a file published target with, so its options apply to the removal as well
+func unpublishedMapping(cfg *config.Config, file, target string) *config.Mapping {
+	result := &config.Mapping{Source: file, Options: &config.Options{}}
+	if matched, ok := cfg.Match(file); ok {
+		result = matched
+	} else if sources := cfg.Sources(file); len(sources) > 0 {
+		result = sources[0]
+	}
+
+	mapping := *result
+	mapping.Target = target
+	return &mapping
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// addFile stages a file of the worktree
+func addFile(worktree *git.Worktree, file string) error {
+	_, err := worktree.Add(filepath.ToSlash(file))
+	if err != nil {
+		return fmt.Errorf("cannot add to worktree: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,152 @@
+package hugo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	gitdiff "gopkg.in/src-d/go-git.v4/utils/diff"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/state"
+)
+
+// PreviewModifiedPages renders the change set exactly like HandleModifiedPages, but instead of committing it
+// it writes a unified diff per target, plus all attachments that would be added or removed, to out
+// the diff is made against current, a tree of the hugo repository, nothing is written to, committed to or
+// pushed from the hugo repository and its worktree is left untouched
+// published is what every logseq file published during the previous syncs, see HandleModifiedPages
+func PreviewModifiedPages(changes *ChangeSet, cfg *config.Config, published map[string]*state.Source, logseqRepository *git.Repository, current *object.Tree, out io.Writer, log *slog.Logger) error {
+	read := func(name string) (string, error) { return readTreeFile(current, name) }
+	r, err := render(changes, cfg, published, logseqRepository, read, log)
+	if err != nil {
+		return err
+	}
+
+	patches := make([]diff.FilePatch, 0)
+	for _, target := range r.removed {
+		content, err := read(target)
+		if err != nil {
+			return err
+		}
+		patches = append(patches, newFilePatch(target, content, true, "", false))
+	}
+
+	for _, page := range r.pages {
+		content, err := read(page.Target)
+		exists := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		if exists && content == page.ParsedContent {
+			continue
+		}
+
+		patches = append(patches, newFilePatch(page.Target, content, exists, page.ParsedContent, true))
+	}
+
+	err = diff.NewUnifiedEncoder(out, diff.DefaultContextLines).Encode(&patch{filePatches: patches})
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range r.attachments {
+		fmt.Fprintf(out, "attachment: %s\n", attachment.name)
+	}
+
+	for _, file := range r.removedAttachments {
+		fmt.Fprintf(out, "removed attachment: %s\n", file)
+	}
+
+	if len(patches) == 0 && len(r.attachments) == 0 && len(r.removedAttachments) == 0 {
+		fmt.Fprintln(out, "dry run: the hugo repository would not change")
+	}
+
+	return nil
+}
+
+// readTreeFile returns the content of the given file of tree, missing files result in os.ErrNotExist
+func readTreeFile(tree *object.Tree, name string) (string, error) {
+	f, err := tree.File(filepath.ToSlash(name))
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return f.Contents()
+}
+
+// the following types implement go-git's diff.Patch, so we can make use of its unified diff encoder
+
+type patch struct {
+	filePatches []diff.FilePatch
+}
+
+func (p *patch) FilePatches() []diff.FilePatch { return p.filePatches }
+func (p *patch) Message() string               { return "" }
+
+type filePatch struct {
+	from, to diff.File
+	chunks   []diff.Chunk
+}
+
+// newFilePatch describes the change of path from one content to another, a missing side is a created or deleted file
+func newFilePatch(path, from string, fromExists bool, to string, toExists bool) *filePatch {
+	p := &filePatch{chunks: make([]diff.Chunk, 0)}
+	if fromExists {
+		p.from = newFile(path, from)
+	}
+	if toExists {
+		p.to = newFile(path, to)
+	}
+
+	for _, d := range gitdiff.Do(from, to) {
+		op := diff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = diff.Add
+		case diffmatchpatch.DiffDelete:
+			op = diff.Delete
+		}
+		p.chunks = append(p.chunks, &chunk{content: d.Text, op: op})
+	}
+
+	return p
+}
+
+func (p *filePatch) IsBinary() bool                { return false }
+func (p *filePatch) Files() (diff.File, diff.File) { return p.from, p.to }
+func (p *filePatch) Chunks() []diff.Chunk          { return p.chunks }
+
+type file struct {
+	path string
+	hash plumbing.Hash
+}
+
+func newFile(path, content string) diff.File {
+	return &file{path: path, hash: plumbing.ComputeHash(plumbing.BlobObject, []byte(content))}
+}
+
+func (f *file) Hash() plumbing.Hash     { return f.hash }
+func (f *file) Mode() filemode.FileMode { return filemode.Regular }
+func (f *file) Path() string            { return f.path }
+
+type chunk struct {
+	content string
+	op      diff.Operation
+}
+
+func (c *chunk) Content() string      { return c.content }
+func (c *chunk) Type() diff.Operation { return c.op }
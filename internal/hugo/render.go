@@ -0,0 +1,165 @@
+package hugo
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/state"
+)
+
+// rendering is everything a change set changes in the hugo repository
+type rendering struct {
+	pages              []*mapping.LogseqPage    // rendered pages, in the order they are written
+	removed            []string                 // targets that are deleted
+	attachments        []attachment             // new or changed attachments
+	removedAttachments []string                 // attachments no published page uses anymore, relative to the hugo repository
+	sources            map[string]*state.Source // logseq file -> what it publishes now, nil for files that publish nothing anymore
+}
+
+// attachment is a file the include attachments option copies to the hugo repository
+type attachment struct {
+	name    string // relative to the hugo repository
+	content []byte
+}
+
+// readFunc returns the content of a file of the hugo repository as it is before the sync, missing files result in os.ErrNotExist
+type readFunc func(name string) (string, error)
+
+// render computes what the change set changes in the hugo repository, without touching it
+// published is what every logseq file published during the previous syncs, read gives access to the current hugo repository
+func render(changes *ChangeSet, cfg *config.Config, published map[string]*state.Source, logseqRepository *git.Repository, read readFunc, log *slog.Logger) (*rendering, error) {
+	logseqWorktree, err := logseqRepository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	// attachments are copied to a scratch directory, from there they're added to the hugo repository along with the pages
+	scratch, err := os.MkdirTemp("", "logsync-attachments-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratch)
+
+	for from, to := range changes.Renamed {
+		log.Info("page renamed", "from", from, "to", to)
+	}
+
+	r := &rendering{pages: make([]*mapping.LogseqPage, 0), removed: make([]string, 0), removedAttachments: make([]string, 0), sources: make(map[string]*state.Source)}
+	for _, file := range changes.Files() {
+		r.sources[file] = nil
+	}
+
+	// all pages of this change set share the index, so the logseq graph is read at most once
+	index := &mapping.Index{}
+	written := make(map[string]string)
+	for _, file := range changes.Modified {
+		matched, ok := cfg.Match(file)
+		if !ok {
+			continue
+		}
+
+		target := *matched
+		options := *target.Options
+		options.HugoRepositoryPath = scratch
+		target.Options = &options
+
+		log.Info("parsing logseq file...", "filename", file)
+		parsedPage, err := mapping.ParsePage(log, filepath.Join(logseqWorktree.Filesystem.Root(), file), &target, logseqRepository, index)
+		if err != nil {
+			return nil, err
+		}
+
+		// with recursion enabled, the linked pages are published along with the page
+		source := &state.Source{Targets: make([]string, 0)}
+		for _, page := range parsedPage.Pages() {
+			r.pages = append(r.pages, page)
+			written[page.Target] = page.ParsedContent
+			source.Targets = append(source.Targets, page.Target)
+		}
+
+		// the linked pages are remembered, so changes to them republish this file
+		for _, linked := range parsedPage.LinkedFiles() {
+			rel, err := filepath.Rel(logseqWorktree.Filesystem.Root(), linked)
+			if err != nil {
+				return nil, err
+			}
+			source.Linked = append(source.Linked, filepath.ToSlash(rel))
+		}
+		r.sources[file] = source
+	}
+
+	// a target that is written again isn't removed, e.g. a renamed page that maps to the same target is simply rewritten
+	unused := make([]string, 0)
+	for _, target := range append(staleTargets(changes, cfg), unpublishedTargets(changes, cfg, published, r.sources)...) {
+		if _, ok := written[target.Target]; ok || slices.Contains(r.removed, target.Target) {
+			continue
+		}
+
+		content, err := read(target.Target)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Info("target of removed page does not exist, skipping", "target", target.Target)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		r.removed = append(r.removed, target.Target)
+		if target.Options != nil && target.Options.RemoveAttachments {
+			unused = append(unused, attachmentsOf(content)...)
+		}
+	}
+
+	r.attachments, err = changedAttachments(filepath.Join(scratch, attachmentDirectory), read)
+	if err != nil {
+		return nil, err
+	}
+
+	r.removedAttachments, err = unusedAttachments(unused, published, r, written, read, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// changedAttachments returns all files of the scratch directory that are missing or differ in the hugo repository
+func changedAttachments(scratchDirectory string, read readFunc) ([]attachment, error) {
+	entries, err := os.ReadDir(scratchDirectory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]attachment, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(scratchDirectory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Join(attachmentDirectory, entry.Name())
+		existing, err := read(name)
+		if err == nil && existing == string(content) {
+			continue
+		}
+
+		result = append(result, attachment{name: name, content: content})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package ingress
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// HTTP exposes the webhook handler on a plain http(s) listener, e.g. behind a reverse proxy
+type HTTP struct {
+	Address   string
+	CertFile  string // serves https if both cert and key file are given
+	KeyFile   string
+	PublicURL string
+}
+
+func (h *HTTP) Start(ctx context.Context, handler http.Handler) (string, chan error, error) {
+	listener, err := net.Listen("tcp", h.Address)
+	if err != nil {
+		return "", nil, err
+	}
+
+	server := newServer(handler)
+	if h.CertFile != "" && h.KeyFile != "" {
+		return h.PublicURL, serve(ctx, server, listener, func(l net.Listener) error {
+			return server.ServeTLS(l, h.CertFile, h.KeyFile)
+		}), nil
+	}
+
+	return h.PublicURL, serve(ctx, server, listener, server.Serve), nil
+}
@@ -0,0 +1,66 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lakrizz/logsync/internal/config"
+)
+
+const (
+	// readHeaderTimeout and readTimeout keep slow clients from holding connections open, webhooks are small
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+)
+
+var (
+	errUnknownIngress = errors.New("unknown ingress type")
+)
+
+// Ingress exposes the webhook handler to github
+type Ingress interface {
+	// Start serves handler until ctx is done, it returns the public url github should deliver webhooks to
+	// and a channel that receives an error if serving stops unexpectedly
+	Start(ctx context.Context, handler http.Handler) (string, chan error, error)
+}
+
+// New returns the ingress configured in cfg
+func New(cfg *config.Config) (Ingress, error) {
+	switch cfg.Ingress.Type {
+	case config.IngressNgrok:
+		return &Ngrok{AuthToken: cfg.Ngrok.AuthToken, PublicURL: cfg.Ingress.PublicURL}, nil
+	case config.IngressHTTP:
+		return &HTTP{Address: cfg.Ingress.Address, CertFile: cfg.Ingress.TLSCertFile, KeyFile: cfg.Ingress.TLSKeyFile, PublicURL: cfg.Ingress.PublicURL}, nil
+	case config.IngressUnix:
+		return &Unix{SocketPath: cfg.Ingress.SocketPath, PublicURL: cfg.Ingress.PublicURL}, nil
+	}
+
+	return nil, errUnknownIngress
+}
+
+// newServer returns a server for handler that doesn't wait forever for slow clients
+func newServer(handler http.Handler) *http.Server {
+	return &http.Server{Handler: handler, ReadHeaderTimeout: readHeaderTimeout, ReadTimeout: readTimeout}
+}
+
+// serve runs server on listener until ctx is done, unexpected errors are sent to the returned channel
+func serve(ctx context.Context, server *http.Server, listener net.Listener, serveFunc func(net.Listener) error) chan error {
+	ch := make(chan error, 1)
+
+	go func() {
+		err := serveFunc(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ch <- err
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	return ch
+}
@@ -0,0 +1,33 @@
+package ingress
+
+import (
+	"context"
+	"net/http"
+
+	"golang.ngrok.com/ngrok"
+	ngrokcfg "golang.ngrok.com/ngrok/config"
+)
+
+// Ngrok exposes the webhook handler through an ngrok tunnel
+type Ngrok struct {
+	AuthToken string
+	PublicURL string // optional, overrides the url of the tunnel
+}
+
+func (n *Ngrok) Start(ctx context.Context, handler http.Handler) (string, chan error, error) {
+	listener, err := ngrok.Listen(ctx,
+		ngrokcfg.HTTPEndpoint(),
+		ngrok.WithAuthtoken(n.AuthToken),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	server := &http.Server{Handler: handler}
+	ch := serve(ctx, server, listener, server.Serve)
+
+	if n.PublicURL != "" {
+		return n.PublicURL, ch, nil
+	}
+	return listener.URL(), ch, nil
+}
@@ -0,0 +1,31 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+)
+
+// Unix exposes the webhook handler on a unix domain socket, e.g. for a reverse proxy on the same host
+type Unix struct {
+	SocketPath string
+	PublicURL  string
+}
+
+func (u *Unix) Start(ctx context.Context, handler http.Handler) (string, chan error, error) {
+	// remove a stale socket of a previous run
+	err := os.Remove(u.SocketPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, err
+	}
+
+	listener, err := net.Listen("unix", u.SocketPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	server := newServer(handler)
+	return u.PublicURL, serve(ctx, server, listener, server.Serve), nil
+}
@@ -0,0 +1,133 @@
+package logseq
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	configFile = "logseq/config.edn"
+
+	// DefaultJournalFileNameFormat is the format of journal filenames logseq uses by default
+	DefaultJournalFileNameFormat = "yyyy_MM_dd"
+)
+
+var (
+	// configs caches the config of every graph until its config.edn changes
+	configs   = make(map[string]*cachedConfig)
+	configsMu sync.Mutex
+)
+
+// GraphConfig holds the settings of a graph's logseq/config.edn that matter for publishing
+type GraphConfig struct {
+	Root                  string   // directory of the graph
+	Hidden                []string // :hidden, paths relative to the root that logseq ignores
+	FileNameFormat        string   // :file/name-format, graphs without it use the legacy format
+	JournalFileNameFormat string   // :journal/file-name-format
+}
+
+type cachedConfig struct {
+	modified time.Time
+	config   *GraphConfig
+}
+
+// DefaultGraphConfig is the config of a graph without logseq/config.edn
+func DefaultGraphConfig(root string) *GraphConfig {
+	return &GraphConfig{Root: root, Hidden: make([]string, 0), FileNameFormat: FileNameFormatTripleLowbar, JournalFileNameFormat: DefaultJournalFileNameFormat}
+}
+
+// LoadConfig reads logseq/config.edn of the graph in root, a graph without one gets the default config
+// the config is only read again once the file changes
+func LoadConfig(root string) (*GraphConfig, error) {
+	filename := filepath.Join(root, filepath.FromSlash(configFile))
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultGraphConfig(root), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	configsMu.Lock()
+	defer configsMu.Unlock()
+
+	if cached, ok := configs[filename]; ok && cached.modified.Equal(info.ModTime()) {
+		return cached.config, nil
+	}
+
+	dat, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfig(root, string(dat))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", configFile, err)
+	}
+
+	configs[filename] = &cachedConfig{modified: info.ModTime(), config: config}
+	return config, nil
+}
+
+func parseConfig(root, input string) (*GraphConfig, error) {
+	value, err := parseEDN(input)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("config is not a map")
+	}
+
+	config := DefaultGraphConfig(root)
+
+	// logseq only writes the filename format to the config of graphs that don't use the legacy format
+	config.FileNameFormat = FileNameFormatLegacy
+	if format, ok := settings[":file/name-format"].(string); ok {
+		config.FileNameFormat = strings.TrimPrefix(format, ":")
+	}
+
+	if format, ok := settings[":journal/file-name-format"].(string); ok && format != "" {
+		config.JournalFileNameFormat = format
+	}
+
+	hidden, _ := settings[":hidden"].([]any)
+	for _, item := range hidden {
+		if p, ok := item.(string); ok && strings.Trim(p, "/") != "" {
+			config.Hidden = append(config.Hidden, strings.Trim(path.Clean("/"+filepath.ToSlash(p)), "/"))
+		}
+	}
+
+	return config, nil
+}
+
+// IsHidden reports whether file, either absolute or relative to the root of the graph, is hidden
+// nothing is hidden without a config
+func (c *GraphConfig) IsHidden(file string) bool {
+	if c == nil {
+		return false
+	}
+
+	if filepath.IsAbs(file) {
+		rel, err := filepath.Rel(c.Root, file)
+		if err != nil {
+			return false
+		}
+		file = rel
+	}
+
+	file = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(file)), "/")
+	for _, hidden := range c.Hidden {
+		if file == hidden || strings.HasPrefix(file, hidden+"/") {
+			return true
+		}
+	}
+	return false
+}
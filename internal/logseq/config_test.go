@@ -0,0 +1,92 @@
+package logseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+const config = `;; logseq config
+{:meta/version 1
+ :preferred-format :markdown ; trailing comment
+ :hidden ["/archive" "pages/secret.md", "/"]
+ #_:hidden #_["ignored"]
+ :journal/file-name-format "yyyy-MM-dd"
+ :file/name-format :triple-lowbar
+ :default-templates {:journals ""}
+ :ref/linkable-properties #{:tags :alias}
+ :graph/settings {:builtin-pages? false :excluded-pages? true}
+ :shortcuts {:editor/new-block "enter"}
+ :query/views {:pages (fn [result] [:div (str "a \"quoted\" " result)])}
+ :feature/enable-whiteboards? true
+ :ui/show-brackets? 1.5}
+`
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+
+	c, err := logseq.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FileNameFormat != logseq.FileNameFormatTripleLowbar || c.JournalFileNameFormat != logseq.DefaultJournalFileNameFormat || len(c.Hidden) != 0 {
+		t.Errorf("unexpected config without config.edn: %+v", c)
+	}
+
+	testutil.WriteFiles(t, root, map[string]string{"logseq/config.edn": config})
+
+	c, err = logseq.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FileNameFormat != logseq.FileNameFormatTripleLowbar || c.JournalFileNameFormat != "yyyy-MM-dd" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+
+	hidden := map[string]bool{
+		"archive/old.md": true,
+		filepath.Join(root, "pages", "secret.md"):   true,
+		"pages/secret.md.bak":                       false,
+		"archived.md":                               false,
+		filepath.Join(root, "pages", "public.md"):   false,
+		filepath.Join(root, "archive", "x", "y.md"): true,
+	}
+	for file, want := range hidden {
+		if got := c.IsHidden(file); got != want {
+			t.Errorf("%s: hidden %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestLoadLegacyConfig(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{"logseq/config.edn": "{:meta/version 1}\n"})
+
+	c, err := logseq.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FileNameFormat != logseq.FileNameFormatLegacy {
+		t.Errorf("graphs without a filename format are legacy graphs, got %q", c.FileNameFormat)
+	}
+
+	err = os.WriteFile(filepath.Join(root, "logseq", "config.edn"), []byte("{:hidden [\"/a\"]"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the cache is invalidated by the modification time, which may not change within the resolution of the file system
+	now := time.Now().Add(time.Minute)
+	err = os.Chtimes(filepath.Join(root, "logseq", "config.edn"), now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = logseq.LoadConfig(root)
+	if err == nil {
+		t.Error("expected an error for a broken config.edn")
+	}
+}
@@ -0,0 +1,216 @@
+package logseq
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of edn")
+
+// ednParser reads the subset of edn that logseq's config.edn consists of: maps, vectors, lists and sets,
+// strings, keywords, symbols, numbers, booleans and nil, everything else is read but not interpreted
+// maps become map[string]any keyed by the printed key (e.g. ":hidden"), collections become []any and
+// keywords stay strings with their leading colon
+type ednParser struct {
+	input []rune
+	pos   int
+}
+
+func parseEDN(input string) (any, error) {
+	p := &ednParser{input: []rune(input)}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q after edn value at %d", p.input[p.pos], p.pos)
+	}
+	return value, nil
+}
+
+// skip skips whitespace, commas, comments and #_ discarded values
+func (p *ednParser) skip() {
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			p.pos++
+		case r == ';':
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		case r == '#' && p.peek(1) == '_':
+			p.pos += 2
+			p.value()
+		default:
+			return
+		}
+	}
+}
+
+func (p *ednParser) peek(offset int) rune {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+func (p *ednParser) value() (any, error) {
+	p.skip()
+	if p.pos >= len(p.input) {
+		return nil, errUnexpectedEnd
+	}
+
+	switch r := p.input[p.pos]; {
+	case r == '{':
+		p.pos++
+		return p.mapValue()
+	case r == '[' || r == '(':
+		p.pos++
+		return p.collection(map[rune]rune{'[': ']', '(': ')'}[r])
+	case r == '#' && p.peek(1) == '{':
+		p.pos += 2
+		return p.collection('}')
+	case r == '#' && p.peek(1) == '"':
+		// regular expressions are kept as their source
+		p.pos++
+		return p.stringValue()
+	case r == '#':
+		// tagged literals, e.g. #inst "...", are read as their value
+		p.pos++
+		p.token()
+		return p.value()
+	case r == '"':
+		return p.stringValue()
+	case r == '\\':
+		p.pos++
+		return p.token(), nil
+	default:
+		return p.atom()
+	}
+}
+
+func (p *ednParser) mapValue() (map[string]any, error) {
+	result := make(map[string]any)
+	for {
+		p.skip()
+		if p.pos >= len(p.input) {
+			return nil, errUnexpectedEnd
+		}
+		if p.input[p.pos] == '}' {
+			p.pos++
+			return result, nil
+		}
+
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		result[fmt.Sprint(key)] = value
+	}
+}
+
+func (p *ednParser) collection(end rune) ([]any, error) {
+	result := make([]any, 0)
+	for {
+		p.skip()
+		if p.pos >= len(p.input) {
+			return nil, errUnexpectedEnd
+		}
+		if p.input[p.pos] == end {
+			p.pos++
+			return result, nil
+		}
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+}
+
+func (p *ednParser) stringValue() (string, error) {
+	p.pos++ // opening quote
+
+	var result strings.Builder
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		p.pos++
+
+		switch r {
+		case '"':
+			return result.String(), nil
+		case '\\':
+			if p.pos >= len(p.input) {
+				return "", errUnexpectedEnd
+			}
+			escaped := p.input[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				result.WriteRune('\n')
+			case 't':
+				result.WriteRune('\t')
+			case 'r':
+				result.WriteRune('\r')
+			default:
+				result.WriteRune(escaped)
+			}
+		default:
+			result.WriteRune(r)
+		}
+	}
+
+	return "", errUnexpectedEnd
+}
+
+// token reads everything up to the next delimiter
+func (p *ednParser) token() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		if unicode.IsSpace(r) || strings.ContainsRune(`,;{}[]()"`, r) {
+			break
+		}
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+func (p *ednParser) atom() (any, error) {
+	token := p.token()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected %q in edn at %d", p.input[p.pos], p.pos)
+	}
+
+	switch token {
+	case "nil":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	if i, err := strconv.ParseInt(token, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return f, nil
+	}
+
+	// keywords and symbols
+	return token, nil
+}
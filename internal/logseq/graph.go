@@ -0,0 +1,81 @@
+package logseq
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graphDirectories are the directories of a logseq graph that contain pages
+var graphDirectories = []string{"pages", "journals"}
+
+// Graph gives access to all pages and referenceable blocks of a logseq graph
+type Graph struct {
+	pages  map[string]*Page         // lower cased page name -> page
+	blocks map[string]*indexedBlock // lower cased uuid -> block
+}
+
+type indexedBlock struct {
+	block *Block
+	page  string
+}
+
+// LoadGraph parses all pages of the logseq graph and indexes their blocks by their `id::` property,
+// hidden pages are no part of the graph
+func LoadGraph(config *GraphConfig) (*Graph, error) {
+	g := &Graph{pages: make(map[string]*Page), blocks: make(map[string]*indexedBlock)}
+
+	for _, directory := range graphDirectories {
+		entries, err := os.ReadDir(filepath.Join(config.Root, directory))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			file := filepath.Join(config.Root, directory, entry.Name())
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" || config.IsHidden(file) {
+				continue
+			}
+
+			dat, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			page := Parse(string(dat))
+			name, ok := page.Title()
+			if !ok {
+				name = PageName(entry.Name(), config.FileNameFormat)
+			}
+			g.pages[strings.ToLower(name)] = page
+
+			page.Walk(func(b *Block) error {
+				if id, ok := b.Properties.Get("id"); ok && id != "" {
+					g.blocks[strings.ToLower(id)] = &indexedBlock{block: b, page: name}
+				}
+				return nil
+			})
+		}
+	}
+
+	return g, nil
+}
+
+// Page returns the page with the given name
+func (g *Graph) Page(name string) (*Page, bool) {
+	page, ok := g.pages[strings.ToLower(name)]
+	return page, ok
+}
+
+// Block returns the block with the given uuid and the name of the page it belongs to
+func (g *Graph) Block(id string) (*Block, string, bool) {
+	b, ok := g.blocks[strings.ToLower(id)]
+	if !ok {
+		return nil, "", false
+	}
+	return b.block, b.page, true
+}
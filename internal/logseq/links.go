@@ -0,0 +1,34 @@
+package logseq
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// LinkRegex matches a [[Page]] link, the first group is the name of the linked page
+	LinkRegex = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+)
+
+// Links returns the names of all pages referenced by [[Page]] links in the order of their first occurrence
+// names are compared case insensitively, links in code are ignored
+func (p *Page) Links() []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+
+	p.ReplaceText(func(text string) string {
+		for _, match := range LinkRegex.FindAllStringSubmatch(text, -1) {
+			// [[Page|label]] links to Page
+			name, _, _ := strings.Cut(match[1], "|")
+			key := strings.ToLower(name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, name)
+		}
+		return text
+	})
+
+	return result
+}
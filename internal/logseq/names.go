@@ -0,0 +1,85 @@
+package logseq
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// FileNameFormatTripleLowbar stores the namespace separator / as ___ and url encodes reserved characters,
+	// it's the format of graphs created by current versions of logseq
+	FileNameFormatTripleLowbar = "triple-lowbar"
+	// FileNameFormatLegacy stores the namespace separator / as . or %2F
+	FileNameFormatLegacy = "legacy"
+)
+
+// PageName returns the name of the page that is encoded in the filename of file, format is the filename format
+// of the graph, an empty format is triple-lowbar
+func PageName(file, format string) string {
+	name := filepath.Base(file)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	separator := "___"
+	if format == FileNameFormatLegacy {
+		separator = "."
+	}
+	name = strings.ReplaceAll(name, separator, "/")
+
+	// names that aren't valid escapes, e.g. 100%.md, are kept as they are
+	if decoded, err := url.PathUnescape(name); err == nil {
+		name = decoded
+	}
+	return name
+}
+
+// Title returns the title:: property of the page, logseq names the page after it instead of its filename
+func (p *Page) Title() (string, bool) {
+	title, ok := p.Properties.Get("title")
+	title = strings.TrimSpace(title)
+	return title, ok && title != ""
+}
+
+// ResolvePageName returns the name of the page stored in file, i.e. its title:: property or the name encoded
+// in its filename, if the file can't be read
+func ResolvePageName(file, format string) string {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return PageName(file, format)
+	}
+
+	if title, ok := Parse(string(dat)).Title(); ok {
+		return title
+	}
+	return PageName(file, format)
+}
+
+// PageFiles maps the lower cased name of every page in the pages directory of the graph to its file,
+// hidden pages are left out
+func PageFiles(config *GraphConfig) (map[string]string, error) {
+	directory := filepath.Join(config.Root, "pages")
+	entries, err := os.ReadDir(directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+
+		file := filepath.Join(directory, entry.Name())
+		if config.IsHidden(file) {
+			continue
+		}
+		files[strings.ToLower(ResolvePageName(file, config.FileNameFormat))] = file
+	}
+
+	return files, nil
+}
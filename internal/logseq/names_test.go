@@ -0,0 +1,48 @@
+package logseq_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestPageName(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+		want   string
+	}{
+		{file: "pages/books___Dune.md", format: logseq.FileNameFormatTripleLowbar, want: "books/Dune"},
+		{file: "pages/What%3F A %22Title%22.md", want: "What? A \"Title\""},
+		{file: "pages/100%.md", want: "100%"},
+		{file: "pages/v1.2 Notes.md", want: "v1.2 Notes"},
+		{file: "pages/books.Dune.md", format: logseq.FileNameFormatLegacy, want: "books/Dune"},
+		{file: "pages/books%2FDune.md", format: logseq.FileNameFormatLegacy, want: "books/Dune"},
+	}
+
+	for _, tt := range tests {
+		if got := logseq.PageName(tt.file, tt.format); got != tt.want {
+			t.Errorf("%s (%s): got %q, want %q", tt.file, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestPageFiles(t *testing.T) {
+	root := t.TempDir()
+	pages := map[string]string{
+		"books___Dune.md": "- the spice\n",
+		"dune_movie.md":   "title:: Dune (Movie)\n\n- the film\n",
+	}
+	testutil.WriteFiles(t, filepath.Join(root, "pages"), pages)
+
+	files, err := logseq.PageFiles(logseq.DefaultGraphConfig(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 || filepath.Base(files["books/dune"]) != "books___Dune.md" || filepath.Base(files["dune (movie)"]) != "dune_movie.md" {
+		t.Errorf("unexpected page files %v", files)
+	}
+}
@@ -0,0 +1,288 @@
+package logseq
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	bulletRegex   = regexp.MustCompile(`^([ \t]*)-(?:[ \t]+(.*)|[ \t]*)$`)
+	propertyRegex = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_\-/]*)::(?:[ \t]+(.*)|[ \t]*)$`)
+	headingRegex  = regexp.MustCompile(`^(#{1,6})[ \t]`)
+)
+
+const (
+	fence = "```"
+	// tabWidth is used to compare the indentation of bullets, logseq either indents with tabs or two spaces
+	tabWidth = 2
+)
+
+// Page is the block tree of a logseq markdown page
+type Page struct {
+	Properties Properties // page properties, given as `key:: value` lines at the very top of the page
+	Preamble   []string   // lines between the page properties and the first block
+	Blocks     []*Block   // top level blocks
+
+	indentUnit      string // indentation of one level, either a tab or spaces
+	trailingNewline bool
+}
+
+// Block is a single bullet point of a logseq page
+type Block struct {
+	Content    string     // content of the block without bullet, indentation and properties, may span multiple lines
+	Properties Properties // block properties, e.g. `id:: 64a1...`
+	Children   []*Block
+
+	// Paragraph renders the block as a plain markdown paragraph instead of a bullet,
+	// its children are rendered one level up
+	Paragraph bool
+}
+
+// Parse builds the block tree of a logseq markdown page
+func Parse(input string) *Page {
+	page := &Page{Properties: make(Properties, 0), Preamble: make([]string, 0), Blocks: make([]*Block, 0), indentUnit: "\t"}
+
+	lines := strings.Split(input, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		page.trailingNewline = true
+		lines = lines[:len(lines)-1]
+	}
+
+	i := 0
+	// page properties
+	for ; i < len(lines); i++ {
+		match := propertyRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			break
+		}
+		page.Properties = append(page.Properties, Property{Key: match[1], Value: match[2]})
+	}
+
+	// everything up to the first bullet
+	for ; i < len(lines); i++ {
+		if bulletRegex.MatchString(lines[i]) {
+			break
+		}
+		page.Preamble = append(page.Preamble, lines[i])
+	}
+
+	type open struct {
+		block  *Block
+		width  int      // width of the bullet's indentation
+		prefix string   // indentation of the bullet
+		lines  []string // content lines
+		fenced bool     // whether the block is currently inside a code fence
+	}
+
+	stack := make([]*open, 0)
+	flush := func(o *open) {
+		o.block.Content, o.block.Properties = splitProperties(o.lines)
+	}
+	indentDetected := false
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		var current *open
+		if len(stack) > 0 {
+			current = stack[len(stack)-1]
+		}
+
+		match := bulletRegex.FindStringSubmatch(line)
+		if match == nil || (current != nil && current.fenced) {
+			// continuation of the current block
+			content := continuation(line, current.prefix)
+			if togglesFence(content) {
+				current.fenced = !current.fenced
+			}
+			current.lines = append(current.lines, content)
+			continue
+		}
+
+		width := indentWidth(match[1])
+		if !indentDetected && width > 0 {
+			page.indentUnit = match[1]
+			indentDetected = true
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].width >= width {
+			flush(stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+		}
+
+		block := &Block{Children: make([]*Block, 0)}
+		o := &open{block: block, width: width, prefix: match[1], lines: []string{match[2]}}
+		if togglesFence(match[2]) {
+			o.fenced = true
+		}
+
+		if len(stack) == 0 {
+			page.Blocks = append(page.Blocks, block)
+		} else {
+			parent := stack[len(stack)-1].block
+			parent.Children = append(parent.Children, block)
+		}
+		stack = append(stack, o)
+	}
+
+	for _, o := range stack {
+		flush(o)
+	}
+
+	return page
+}
+
+// Extract returns a new page of the given properties, preamble and blocks that is indented like p
+func (p *Page) Extract(properties Properties, preamble []string, blocks []*Block) *Page {
+	return &Page{Properties: properties, Preamble: preamble, Blocks: blocks, indentUnit: p.indentUnit, trailingNewline: true}
+}
+
+// Walk calls fn for every block of the page, parents are visited before their children
+func (p *Page) Walk(fn func(b *Block) error) error {
+	return walk(p.Blocks, fn)
+}
+
+// Walk calls fn for the block and all of its descendants, parents are visited before their children
+func (b *Block) Walk(fn func(b *Block) error) error {
+	err := fn(b)
+	if err != nil {
+		return err
+	}
+	return walk(b.Children, fn)
+}
+
+func walk(blocks []*Block, fn func(b *Block) error) error {
+	for _, b := range blocks {
+		err := fn(b)
+		if err != nil {
+			return err
+		}
+
+		err = walk(b.Children, fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceText applies fn to the preamble and the content of all blocks, code is left untouched
+func (p *Page) ReplaceText(fn func(text string) string) {
+	if len(p.Preamble) > 0 {
+		p.Preamble = strings.Split(ReplaceText(strings.Join(p.Preamble, "\n"), fn), "\n")
+	}
+	p.Walk(func(b *Block) error {
+		b.Content = ReplaceText(b.Content, fn)
+		return nil
+	})
+}
+
+// Heading returns the level of the markdown heading the block starts with, or 0 if it's no heading
+func (b *Block) Heading() int {
+	match := headingRegex.FindStringSubmatch(b.Content)
+	if match == nil {
+		return 0
+	}
+	return len(match[1])
+}
+
+// Title returns the first line of the block without its heading marker
+func (b *Block) Title() string {
+	title, _, _ := strings.Cut(b.Content, "\n")
+	return strings.TrimSpace(headingRegex.ReplaceAllString(title, ""))
+}
+
+// Clone returns a deep copy of the block and its children
+func (b *Block) Clone() *Block {
+	clone := &Block{Content: b.Content, Properties: append(Properties{}, b.Properties...), Children: make([]*Block, 0, len(b.Children)), Paragraph: b.Paragraph}
+	for _, child := range b.Children {
+		clone.Children = append(clone.Children, child.Clone())
+	}
+	return clone
+}
+
+// IsEmpty reports whether the block has neither content, properties nor children
+func (b *Block) IsEmpty() bool {
+	return strings.TrimSpace(b.Content) == "" && len(b.Properties) == 0 && len(b.Children) == 0
+}
+
+// ReplaceText applies fn to all parts of content that are neither in a code fence nor in inline code
+func ReplaceText(content string, fn func(text string) string) string {
+	lines := strings.Split(content, "\n")
+	fenced := false
+
+	for i, line := range lines {
+		if togglesFence(line) {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		// every odd part is enclosed in backticks
+		parts := strings.Split(line, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = fn(parts[j])
+		}
+		if len(parts)%2 == 0 {
+			// an unclosed backtick is no inline code, so the last part is regular text
+			parts[len(parts)-1] = fn(parts[len(parts)-1])
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// splitProperties separates the property lines of a block from its content, properties in code fences are ignored
+func splitProperties(lines []string) (string, Properties) {
+	properties := make(Properties, 0)
+	content := make([]string, 0, len(lines))
+	fenced := false
+
+	for _, line := range lines {
+		if togglesFence(line) {
+			fenced = !fenced
+		}
+
+		if !fenced {
+			if match := propertyRegex.FindStringSubmatch(line); match != nil {
+				properties = append(properties, Property{Key: match[1], Value: match[2]})
+				continue
+			}
+		}
+
+		content = append(content, line)
+	}
+
+	return strings.Join(content, "\n"), properties
+}
+
+// continuation strips the indentation of a block's bullet (plus the two characters of "- ") from a continuation line
+func continuation(line, prefix string) string {
+	line = strings.TrimPrefix(line, prefix)
+	for i := 0; i < 2 && len(line) > 0 && line[0] == ' '; i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// togglesFence reports whether line opens or closes a code fence, a fence that is closed on the same line does neither
+func togglesFence(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Count(line, fence)%2 == 1
+}
+
+func indentWidth(indent string) int {
+	width := 0
+	for _, c := range indent {
+		if c == '\t' {
+			width += tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
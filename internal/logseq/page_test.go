@@ -0,0 +1,87 @@
+package logseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+const page = `title:: My Page
+tags:: foo, bar
+
+- # first
+  id:: 6650a1b2-0000-4000-8000-000000000000
+	- child with
+	  a second line
+		- grandchild
+	- ` + "```go" + `
+	  - not a bullet
+	  id:: not a property
+	  ` + "```" + `
+- second
+`
+
+func TestParse(t *testing.T) {
+	p := logseq.Parse(page)
+
+	if v, ok := p.Properties.Get("Title"); !ok || v != "My Page" {
+		t.Fatalf("unexpected title: %q", v)
+	}
+
+	if len(p.Blocks) != 2 {
+		t.Fatalf("expected 2 top level blocks, got %d", len(p.Blocks))
+	}
+
+	first := p.Blocks[0]
+	if first.Heading() != 1 || first.Content != "# first" {
+		t.Fatalf("unexpected first block: %q", first.Content)
+	}
+
+	if id, ok := first.Properties.Get("id"); !ok || !strings.HasPrefix(id, "6650a1b2") {
+		t.Fatalf("block property was not parsed: %q", id)
+	}
+
+	if len(first.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(first.Children))
+	}
+
+	if first.Children[0].Content != "child with\na second line" {
+		t.Fatalf("unexpected multi line content: %q", first.Children[0].Content)
+	}
+
+	if len(first.Children[0].Children) != 1 {
+		t.Fatal("grandchild was not nested")
+	}
+
+	code := first.Children[1]
+	if len(code.Children) != 0 || len(code.Properties) != 0 || !strings.Contains(code.Content, "- not a bullet") {
+		t.Fatalf("code fence was parsed as blocks: %q", code.Content)
+	}
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		page,
+		"- a\n  - b\n    - c\n- d",
+		"some text before\n\n- a\n-\n",
+		"",
+	} {
+		output := logseq.Parse(input).Render()
+		if output != input {
+			t.Errorf("round trip changed page\n got: %q\nwant: %q", output, input)
+		}
+	}
+}
+
+func TestReplaceText(t *testing.T) {
+	p := logseq.Parse("- [[a]] `[[b]]`\n- ```\n  [[c]]\n  ```\n")
+	p.ReplaceText(func(text string) string {
+		return strings.ReplaceAll(text, "[[", "((")
+	})
+
+	want := "- ((a]] `[[b]]`\n- ```\n  [[c]]\n  ```\n"
+	if got := p.Render(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package logseq
+
+import "strings"
+
+// Property is a single `key:: value` pair of a page or a block
+type Property struct {
+	Key   string
+	Value string
+}
+
+// Properties keeps the properties in the order they were written
+type Properties []Property
+
+// Get returns the value of the property key, keys are compared case insensitively like logseq does
+func (p Properties) Get(key string) (string, bool) {
+	for _, property := range p {
+		if strings.EqualFold(property.Key, key) {
+			return property.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set replaces the value of the property key or appends it if it does not exist yet
+func (p *Properties) Set(key, value string) {
+	for i, property := range *p {
+		if strings.EqualFold(property.Key, key) {
+			(*p)[i].Value = value
+			return
+		}
+	}
+	*p = append(*p, Property{Key: key, Value: value})
+}
+
+// Delete removes the property key
+func (p *Properties) Delete(key string) {
+	result := (*p)[:0]
+	for _, property := range *p {
+		if !strings.EqualFold(property.Key, key) {
+			result = append(result, property)
+		}
+	}
+	*p = result
+}
@@ -0,0 +1,97 @@
+package logseq
+
+import (
+	"strings"
+)
+
+// Render turns the page back into logseq markdown
+// an unmodified page renders to its input, apart from normalized whitespace around bullets and properties
+func (p *Page) Render() string {
+	lines := make([]string, 0)
+
+	for _, property := range p.Properties {
+		lines = append(lines, renderProperty(property))
+	}
+	lines = append(lines, p.Preamble...)
+	lines = p.renderBlocks(lines, p.Blocks, 0)
+
+	result := strings.Join(lines, "\n")
+	if p.trailingNewline {
+		result += "\n"
+	}
+	return result
+}
+
+func (p *Page) renderBlocks(lines []string, blocks []*Block, depth int) []string {
+	indent := strings.Repeat(p.indentUnit, depth)
+
+	afterParagraph := false
+	for _, b := range blocks {
+		if b.Paragraph {
+			lines = p.renderParagraph(lines, b, depth)
+			afterParagraph = true
+			continue
+		}
+
+		if afterParagraph {
+			lines = separate(lines)
+			afterParagraph = false
+		}
+
+		content := strings.Split(b.Content, "\n")
+
+		bullet := indent + "-"
+		if content[0] != "" {
+			bullet += " " + content[0]
+		}
+		lines = append(lines, bullet)
+
+		for _, property := range b.Properties {
+			lines = append(lines, indent+"  "+renderProperty(property))
+		}
+
+		for _, line := range content[1:] {
+			if line == "" {
+				lines = append(lines, "")
+				continue
+			}
+			lines = append(lines, indent+"  "+line)
+		}
+
+		lines = p.renderBlocks(lines, b.Children, depth+1)
+	}
+
+	return lines
+}
+
+func (p *Page) renderParagraph(lines []string, b *Block, depth int) []string {
+	if strings.TrimSpace(b.Content) != "" {
+		lines = separate(lines)
+		lines = append(lines, strings.Split(b.Content, "\n")...)
+		for _, property := range b.Properties {
+			lines = append(lines, renderProperty(property))
+		}
+	}
+
+	if len(b.Children) > 0 {
+		lines = separate(lines)
+		lines = p.renderBlocks(lines, b.Children, depth)
+	}
+
+	return lines
+}
+
+// separate ends the current markdown paragraph or list with an empty line
+func separate(lines []string) []string {
+	if len(lines) == 0 || lines[len(lines)-1] == "" {
+		return lines
+	}
+	return append(lines, "")
+}
+
+func renderProperty(property Property) string {
+	if property.Value == "" {
+		return property.Key + "::"
+	}
+	return property.Key + ":: " + property.Value
+}
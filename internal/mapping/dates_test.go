@@ -0,0 +1,60 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestDatesFromHistory(t *testing.T) {
+	root := t.TempDir()
+	repo, err := gogit.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(content string, when time.Time) {
+		testutil.CommitFilesAt(t, repo, map[string]string{"pages/post.md": content}, when)
+	}
+
+	render := func() string {
+		m := &config.Mapping{Target: "content/post.md", Frontmatter: map[string]any{}, Options: &config.Options{LogseqRepositoryPath: root}}
+		page, err := mapping.ParsePage(slog.Default(), filepath.Join(root, "pages", "post.md"), m, repo, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page.ParsedContent
+	}
+
+	commit("- first\n", time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC))
+	commit("- second\n", time.Date(2024, 6, 14, 10, 0, 0, 0, time.UTC))
+
+	content := render()
+	for _, s := range []string{"date = 2024-06-12T10:00:00Z\n", "lastmod = 2024-06-14T10:00:00Z\n"} {
+		if !strings.Contains(content, s) {
+			t.Errorf("%q is missing in\n%s", s, content)
+		}
+	}
+
+	if render() != content {
+		t.Error("rendering the same page twice differs")
+	}
+
+	// an explicit date wins
+	commit("date:: [[Jun 1st, 2024]]\n\n- third\n", time.Date(2024, 6, 16, 10, 0, 0, 0, time.UTC))
+
+	content = render()
+	for _, s := range []string{"date = 2024-06-01T00:00:00Z\n", "lastmod = 2024-06-16T10:00:00Z\n"} {
+		if !strings.Contains(content, s) {
+			t.Errorf("%q is missing in\n%s", s, content)
+		}
+	}
+}
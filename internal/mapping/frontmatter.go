@@ -1,33 +1,48 @@
 package mapping
 
 import (
-	"fmt"
-	"path/filepath"
+	"maps"
 	"strings"
-	"time"
-
-	"github.com/gosimple/slug"
 
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/frontmatter"
+	"github.com/lakrizz/logsync/internal/logseq"
 )
 
 func (l *LogseqPage) addFrontMatter(mapping *config.Mapping) error {
-	sb := strings.Builder{}
+	// the mapping is shared by all pages (and concurrent syncs), so the front matter of this page is a copy of it
+	values := make(map[string]any, len(mapping.Frontmatter)+3)
+	maps.Copy(values, mapping.Frontmatter)
+
+	// static frontmatter (e.g., date), the dates come from the history of the page, so they don't change on every sync
+	values["date"] = l.created
+	values["lastmod"] = l.modified
+	values["title"] = l.pageTitle(mapping.Options)
+
+	// page properties take precedence, they're specific to this very page
+	for k, v := range frontMatterProperties(l.Properties, mapping.Properties) {
+		values[k] = v
+	}
+
+	document, err := frontmatter.Encode(mapping.FrontmatterFormat, values)
+	if err != nil {
+		return err
+	}
 
-	// input filename without ext
-	fileWithoutExtension := l.InputFilename[:strings.LastIndex(l.InputFilename, filepath.Ext(l.InputFilename))]
+	l.ParsedContent = document + l.ParsedContent
+	return nil
+}
 
-	// static frontmatter (e.g., date)
-	mapping.Frontmatter["date"] = time.Now().Format(time.RFC3339)
-	mapping.Frontmatter["title"] = slug.Make(fileWithoutExtension)
+// pageTitle returns the title of the page: the title of a journal post, the title:: property of the page
+// or the name encoded in its filename, the case of the name is kept
+func (l *LogseqPage) pageTitle(opts *config.Options) string {
+	if l.title != "" {
+		return l.title
+	}
 
-	for k, v := range mapping.Frontmatter {
-		_, err := sb.WriteString(fmt.Sprintf("%s = '%v'\n", k, v))
-		if err != nil {
-			return err
-		}
+	if title, ok := l.Properties.Get("title"); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
 	}
 
-	l.ParsedContent = fmt.Sprintf("+++\n%v+++\n%v", sb.String(), l.ParsedContent)
-	return nil
+	return logseq.PageName(l.InputFilename, opts.Graph().FileNameFormat)
 }
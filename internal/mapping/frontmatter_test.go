@@ -0,0 +1,72 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestFrontMatterKeepsMapping(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"first.md": "- the body\n", "second.md": "- the body\n"})
+
+	t.Run("without front matter", func(t *testing.T) {
+		m := &config.Mapping{Target: "content/first.md", Options: &config.Options{}}
+
+		page, err := mapping.ParsePage(slog.Default(), filepath.Join(dir, "first.md"), m, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(page.ParsedContent, `title = "first"`) {
+			t.Errorf("title is missing in\n%s", page.ParsedContent)
+		}
+		if m.Frontmatter != nil {
+			t.Errorf("the mapping was changed: %v", m.Frontmatter)
+		}
+	})
+
+	t.Run("shared front matter", func(t *testing.T) {
+		m := &config.Mapping{Target: "content/page.md", Frontmatter: map[string]any{"author": "me"}, Options: &config.Options{}}
+
+		for _, name := range []string{"first", "second"} {
+			page, err := mapping.ParsePage(slog.Default(), filepath.Join(dir, name+".md"), m, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(page.ParsedContent, `title = "`+name+`"`) || !strings.Contains(page.ParsedContent, `author = "me"`) {
+				t.Errorf("unexpected front matter in\n%s", page.ParsedContent)
+			}
+		}
+
+		if len(m.Frontmatter) != 1 || m.Frontmatter["author"] != "me" {
+			t.Errorf("the mapping was changed: %v", m.Frontmatter)
+		}
+	})
+
+	t.Run("title", func(t *testing.T) {
+		files := map[string]string{
+			"books___Dune.md": "- the spice\n",
+			"dune_movie.md":   "title:: Dune (Movie)\n\n- the film\n",
+		}
+		want := map[string]string{"books___Dune.md": `title = "books/Dune"`, "dune_movie.md": `title = "Dune (Movie)"`}
+
+		for name, content := range files {
+			testutil.WriteFiles(t, dir, map[string]string{name: content})
+
+			// the title is kept even if the title:: property isn't part of the front matter
+			m := &config.Mapping{Target: "content/page.md", Properties: map[string]string{}, Options: &config.Options{}}
+			page, err := mapping.ParsePage(slog.Default(), filepath.Join(dir, name), m, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(page.ParsedContent, want[name]) {
+				t.Errorf("%q is missing in\n%s", want[name], page.ParsedContent)
+			}
+		}
+	})
+}
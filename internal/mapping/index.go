@@ -0,0 +1,83 @@
+package mapping
+
+import (
+	"fmt"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/git"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
+)
+
+// Index holds everything pages look up in the whole logseq graph, it's shared by all pages of a sync,
+// so the graph is read at most once per sync instead of once per page
+// everything is loaded on first use, the zero value is ready to use
+// the index depends only on the values all options of a config share (see config.SetStaticValuesForAllOptions)
+type Index struct {
+	graph  *logseq.Graph        // all pages and referenceable blocks
+	files  map[string]string    // lower cased page name -> file of every page
+	mapped map[string]string    // lower cased page name -> target of every page published by a mapping
+	dates  map[string]git.Dates // file -> dates of its first and latest commit
+}
+
+// Graph returns the parsed logseq graph
+func (i *Index) Graph(opts *config.Options) (*logseq.Graph, error) {
+	if i.graph != nil {
+		return i.graph, nil
+	}
+
+	graph, err := logseq.LoadGraph(opts.Graph())
+	if err != nil {
+		return nil, fmt.Errorf("cannot load logseq graph: %w", err)
+	}
+
+	i.graph = graph
+	return graph, nil
+}
+
+// Files maps the lower cased name of every page to its file
+func (i *Index) Files(opts *config.Options) (map[string]string, error) {
+	if i.files != nil {
+		return i.files, nil
+	}
+
+	files, err := logseq.PageFiles(opts.Graph())
+	if err != nil {
+		return nil, err
+	}
+
+	i.files = files
+	return files, nil
+}
+
+// Mapped maps the lower cased name of every page published by a mapping to its target
+func (i *Index) Mapped(opts *config.Options) (map[string]string, error) {
+	if i.mapped != nil {
+		return i.mapped, nil
+	}
+
+	mapped, err := option.MappedPages(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mapped = mapped
+	return mapped, nil
+}
+
+// History returns the dates of every file of the logseq repository, its history is walked only once per index
+func (i *Index) History(repo *gogit.Repository) (map[string]git.Dates, error) {
+	if i.dates != nil {
+		return i.dates, nil
+	}
+
+	dates, err := git.History(repo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history: %w", err)
+	}
+
+	i.dates = dates
+	return dates, nil
+}
@@ -0,0 +1,95 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestIndexIsSharedByPages(t *testing.T) {
+	logseq := t.TempDir()
+	testutil.WriteFiles(t, logseq, map[string]string{
+		"pages/First.md":  "- as said in ((6650a1b2-0000-4000-8000-000000000001))\n",
+		"pages/Second.md": "- again ((6650a1b2-0000-4000-8000-000000000001))\n",
+		"pages/Quotes.md": "- a quote\n  id:: 6650a1b2-0000-4000-8000-000000000001\n",
+	})
+
+	m := &config.Mapping{
+		Target:      "content/page.md",
+		Frontmatter: map[string]any{},
+		Options:     &config.Options{ResolveBlockReferences: true, LogseqRepositoryPath: logseq},
+	}
+
+	index := &mapping.Index{}
+	first, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, "pages", "First.md"), m, nil, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(first.ParsedContent, "as said in a quote") {
+		t.Fatalf("reference was not resolved:\n%s", first.ParsedContent)
+	}
+
+	// the graph was read for the first page already, so the second one doesn't notice the page is gone
+	err = os.Remove(filepath.Join(logseq, "pages", "Quotes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, "pages", "Second.md"), m, nil, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(second.ParsedContent, "again a quote") {
+		t.Fatalf("graph was read again:\n%s", second.ParsedContent)
+	}
+
+	// without an index, the graph is read for every page
+	second, err = mapping.ParsePage(slog.Default(), filepath.Join(logseq, "pages", "Second.md"), m, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(second.ParsedContent, "a quote") {
+		t.Fatalf("removed page was resolved:\n%s", second.ParsedContent)
+	}
+}
+
+func TestIndexMapsPagesOnce(t *testing.T) {
+	logseq := t.TempDir()
+	testutil.WriteFiles(t, logseq, map[string]string{
+		"pages/First.md":        "- read [[books/Dune]] and [[books/Emma]]\n",
+		"pages/books___Dune.md": "- the spice\n",
+	})
+
+	books := &config.Mapping{Source: "books/*", Target: "content/books/{{ .Slug }}.md"}
+	m := &config.Mapping{
+		Target:      "content/first.md",
+		Frontmatter: map[string]any{},
+		Options:     &config.Options{RewriteLinks: true, LogseqRepositoryPath: logseq, MappedPatterns: []*config.Mapping{books}},
+	}
+
+	index := &mapping.Index{}
+	page, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, "pages", "First.md"), m, nil, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.ParsedContent, "[books/Dune](/books/dune/)") {
+		t.Fatalf("link to mapped page was not rewritten:\n%s", page.ParsedContent)
+	}
+
+	// the mapped pages are only looked up once per index
+	testutil.WriteFiles(t, logseq, map[string]string{"pages/books___Emma.md": "- a new book\n"})
+
+	page, err = mapping.ParsePage(slog.Default(), filepath.Join(logseq, "pages", "First.md"), m, nil, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page.ParsedContent, "/books/emma/") {
+		t.Fatalf("mapped pages were looked up again:\n%s", page.ParsedContent)
+	}
+}
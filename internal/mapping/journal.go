@@ -0,0 +1,169 @@
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+var (
+	errNoJournal     = errors.New("filename doesn't match the journal filename format")
+	errDuplicatePost = errors.New("posts of the same title have the same target, add the .Slug or .Title to the target")
+
+	// titleLinkRegex matches [[Page]] and #[[Page]] links, their page name is used in titles
+	titleLinkRegex = regexp.MustCompile(`#?\[\[([^\[\]]+)\]\]`)
+
+	// postIgnoredProperties are block properties of tagged blocks that don't become properties of their post
+	postIgnoredProperties = []string{"id", "collapsed"}
+)
+
+// journalPost is a single dated post of a journal
+type journalPost struct {
+	title   string
+	content string
+}
+
+// parseJournal converts a journal to dated posts, either the whole journal or every top level block that is tagged
+// with the tag of the journal mapping, the posts are the linked pages of the returned page
+func (p *pipeline) parseJournal(filename string) (*LogseqPage, error) {
+	date, ok := p.mapping.JournalDate(filename)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", filename, errNoJournal)
+	}
+
+	l := &LogseqPage{logger: p.log, InputFilename: filepath.Base(filename), Linked: make([]*LogseqPage, 0), skip: true, pipeline: p}
+	err := l.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.readHistory(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	journal := logseq.Parse(l.InputContent)
+	posts := make([]journalPost, 0)
+	if p.mapping.Journal.Tag == "" {
+		title := ""
+		if len(journal.Blocks) > 0 {
+			title = postTitle(journal.Blocks[0].Title(), nil)
+		}
+		posts = append(posts, journalPost{title: title, content: l.InputContent})
+	} else {
+		tag := tagRegex(p.mapping.Journal.Tag)
+		for _, b := range journal.Blocks {
+			if !isTagged(b, p.mapping.Journal.Tag, tag) {
+				continue
+			}
+			posts = append(posts, journalPost{title: postTitle(b.Title(), tag), content: postPage(journal, b).Render()})
+		}
+	}
+
+	targets := make(map[string]bool)
+	for _, post := range posts {
+		if post.title == "" {
+			post.title = date.Format("January 2, 2006")
+		}
+
+		target, err := p.postTarget(post.title, targets)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		page := &LogseqPage{logger: p.log, InputContent: post.content, InputFilename: l.InputFilename, Target: target, Linked: make([]*LogseqPage, 0), pipeline: p}
+		page.title = post.title
+		page.created, page.modified = date, l.modified
+
+		err = page.parseOptions(p.mapping.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		err = page.addFrontMatter(p.mapping)
+		if err != nil {
+			return nil, err
+		}
+
+		l.Linked = append(l.Linked, page)
+	}
+
+	return l, nil
+}
+
+// postTarget returns the target of a post that isn't taken by another post of the journal yet, posts of the same title
+// (e.g. untitled posts of the same day) are numbered in the order they appear in, so they don't overwrite each other
+func (p *pipeline) postTarget(title string, taken map[string]bool) (string, error) {
+	for n := 1; n <= len(taken)+1; n++ {
+		target, err := p.mapping.JournalTarget(title, n)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken[target] {
+			taken[target] = true
+			return target, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w", title, errDuplicatePost)
+}
+
+// postPage turns a tagged block into a page of its own: the block properties become page properties,
+// the first line is the title of the post and the rest of the block and its children are the body
+func postPage(journal *logseq.Page, b *logseq.Block) *logseq.Page {
+	properties := make(logseq.Properties, 0)
+	for _, property := range b.Properties {
+		if !slices.Contains(postIgnoredProperties, strings.ToLower(property.Key)) {
+			properties = append(properties, property)
+		}
+	}
+
+	preamble := make([]string, 0)
+	if _, rest, ok := strings.Cut(b.Content, "\n"); ok {
+		preamble = append(preamble, strings.Split(rest, "\n")...)
+	}
+
+	blocks := make([]*logseq.Block, 0, len(b.Children))
+	for _, child := range b.Children {
+		blocks = append(blocks, child.Clone())
+	}
+
+	return journal.Extract(properties, preamble, blocks)
+}
+
+// tagRegex matches #tag, #[[tag]] and [[tag]] including the whitespace around it
+func tagRegex(tag string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(tag)
+	return regexp.MustCompile(`(?i)(^|\s)(?:#?\[\[` + quoted + `\]\]|#` + quoted + `)(\s|$)`)
+}
+
+// isTagged reports whether the first line of the block contains the tag or the block has it as tags:: property
+func isTagged(b *logseq.Block, tag string, regex *regexp.Regexp) bool {
+	first, _, _ := strings.Cut(b.Content, "\n")
+	if regex.MatchString(first) {
+		return true
+	}
+
+	tags, _ := b.Properties.Get("tags")
+	for _, item := range strings.Split(tags, ",") {
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(item), "#[]"), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// postTitle removes the tag from the first line of a post and replaces links with the name of their page
+func postTitle(line string, tag *regexp.Regexp) string {
+	if tag != nil {
+		line = tag.ReplaceAllString(line, "$1$2")
+	}
+	line = titleLinkRegex.ReplaceAllString(line, "$1")
+	return strings.Join(strings.Fields(line), " ")
+}
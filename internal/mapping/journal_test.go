@@ -0,0 +1,136 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestJournal(t *testing.T) {
+	logseq := t.TempDir()
+	journal := "- ## Trip to [[Lisbon]] #blog\n  tags:: travel\n  id:: 6650a1b2-0000-4000-8000-000000000001\n\t- it was sunny\n- a private thought\n- #[[blog]] Second post\n"
+	testutil.WriteFiles(t, logseq, map[string]string{"journals/2024_06_12.md": journal})
+
+	tests := []struct {
+		name    string
+		journal config.Journal
+		want    map[string][]string
+		missing []string
+	}{
+		{
+			name:    "tagged blocks",
+			journal: config.Journal{Tag: "blog"},
+			want: map[string][]string{
+				"content/posts/2024-06-12-trip-to-lisbon.md": {`title = "Trip to Lisbon"`, `tags = ["travel"]`, "date = 2024-06-12T00:00:00Z", "- it was sunny"},
+				"content/posts/2024-06-12-second-post.md":    {`title = "Second post"`},
+			},
+			missing: []string{"private", "id::", "#blog"},
+		},
+		{
+			name: "whole journal",
+			want: map[string][]string{
+				"content/posts/2024-06-12-trip-to-lisbon-blog.md": {`title = "Trip to Lisbon #blog"`, "date = 2024-06-12T00:00:00Z", "a private thought", "Second post"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &config.Mapping{
+				Source:  "journals/*.md",
+				Target:  "content/posts/{{ .Date }}-{{ .Slug }}.md",
+				Journal: &tt.journal,
+				Options: &config.Options{LogseqRepositoryPath: logseq},
+			}
+
+			matched, ok := m.Match("journals/2024_06_12.md")
+			// the targets of the posts are only known once the journal is parsed
+			if !ok || matched.Target != "" {
+				t.Fatalf("journal was not matched: %+v", matched)
+			}
+
+			page, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, matched.Source), matched, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			published := make(map[string]string)
+			for _, p := range page.Pages() {
+				published[p.Target] = p.ParsedContent
+			}
+			if len(published) != len(tt.want) {
+				t.Fatalf("unexpected posts %v", published)
+			}
+
+			for target, want := range tt.want {
+				content, ok := published[target]
+				if !ok {
+					t.Fatalf("%s was not published: %v", target, published)
+				}
+
+				for _, s := range want {
+					if !strings.Contains(content, s) {
+						t.Errorf("%q is missing in\n%s", s, content)
+					}
+				}
+
+				for _, s := range tt.missing {
+					if strings.Contains(content, s) {
+						t.Errorf("%q should not be part of\n%s", s, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJournalPostsOfTheSameTitle(t *testing.T) {
+	logseq := t.TempDir()
+	journal := "- #blog\n\t- first untitled\n- #blog\n\t- second untitled\n- Same #blog\n\t- first same\n- Same #blog\n\t- second same\n"
+	testutil.WriteFiles(t, logseq, map[string]string{"journals/2024_06_12.md": journal})
+
+	m := &config.Mapping{
+		Source:  "journals/*.md",
+		Target:  "content/posts/{{ .Date }}-{{ .Slug }}.md",
+		Journal: &config.Journal{Tag: "blog"},
+		Options: &config.Options{LogseqRepositoryPath: logseq},
+	}
+
+	matched, ok := m.Match("journals/2024_06_12.md")
+	if !ok {
+		t.Fatal("journal was not matched")
+	}
+
+	page, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, matched.Source), matched, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"content/posts/2024-06-12-june-12-2024.md":   "first untitled",
+		"content/posts/2024-06-12-june-12-2024-2.md": "second untitled",
+		"content/posts/2024-06-12-same.md":           "first same",
+		"content/posts/2024-06-12-same-2.md":         "second same",
+	}
+
+	pages := page.Pages()
+	if len(pages) != len(want) {
+		t.Fatalf("expected %d posts, got %d", len(want), len(pages))
+	}
+
+	for _, p := range pages {
+		content, ok := want[p.Target]
+		if !ok {
+			t.Errorf("unexpected post %s", p.Target)
+			continue
+		}
+		if !strings.Contains(p.ParsedContent, content) {
+			t.Errorf("%s: %q is missing in\n%s", p.Target, content, p.ParsedContent)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package option
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+// maxReferenceDepth limits how deep references within referenced blocks are resolved
+const maxReferenceDepth = 5
+
+var (
+	// matches, in this order, {{embed ((uuid))}}, {{embed [[Page]]}} and ((uuid))
+	referenceRegex = regexp.MustCompile(`\{\{embed\s+\(\(([0-9a-fA-F-]{36})\)\)\s*\}\}|\{\{embed\s+\[\[([^\[\]]+)\]\]\s*\}\}|\(\(([0-9a-fA-F-]{36})\)\)`)
+	headingPrefix  = regexp.MustCompile(`^#{1,6}\s+`)
+)
+
+// BlockReferences replaces ((uuid)) block references with the text of the referenced block and inlines
+// {{embed ((uuid))}} and {{embed [[Page]]}} macros, blocks are looked up in the whole logseq graph
+type BlockReferences struct {
+	Graph  *logseq.Graph
+	Link   bool // whether references to blocks of published pages link to the block
+	Format string
+
+	// Pages maps the lower cased name of every published page to its target
+	Pages map[string]string
+
+	// Mapped maps the lower cased name of every page published by a mapping to its target (see MappedPages),
+	// they're added to Pages
+	Mapped map[string]string
+}
+
+func (r *BlockReferences) IsEnabled(opts *config.Options) (bool, error) {
+	if !opts.ResolveBlockReferences || r.Graph == nil {
+		return false, nil
+	}
+
+	r.Link = opts.LinkBlockReferences
+	r.Format = opts.LinkFormat
+	if r.Format == "" {
+		r.Format = config.LinkFormatMarkdown
+	}
+	if r.Format != config.LinkFormatMarkdown && r.Format != config.LinkFormatRelref {
+		return false, errUnknownLinkFormat
+	}
+
+	if r.Pages == nil {
+		r.Pages = make(map[string]string)
+	}
+	addMappedPages(r.Pages, r.Mapped)
+
+	return true, nil
+}
+
+func (r *BlockReferences) Apply(page *logseq.Page) error {
+	// blocks that consist of nothing but an embed take over the embedded content along with its children,
+	// all other embeds and references are replaced inline
+	page.Blocks = r.embed(page.Blocks, map[string]bool{})
+
+	page.ReplaceText(func(text string) string {
+		return r.resolve(text, 0)
+	})
+
+	if r.Link {
+		// other pages link to the blocks of this page, so they need an anchor
+		page.Walk(func(b *logseq.Block) error {
+			id, ok := b.Properties.Get("id")
+			if !ok || id == "" || strings.HasPrefix(strings.TrimSpace(b.Content), "```") {
+				return nil
+			}
+
+			first, rest, _ := strings.Cut(b.Content, "\n")
+			b.Content = fmt.Sprintf(`%s <a id="%s"></a>`, first, anchor(id))
+			if rest != "" {
+				b.Content += "\n" + rest
+			}
+			return nil
+		})
+	}
+
+	return nil
+}
+
+// embed expands all blocks that consist of an embed macro, seen holds the embeds that are currently expanded
+// so embeds that embed themselves end
+func (r *BlockReferences) embed(blocks []*logseq.Block, seen map[string]bool) []*logseq.Block {
+	for _, b := range blocks {
+		children := r.embed(b.Children, seen)
+
+		content := strings.TrimSpace(b.Content)
+		match := referenceRegex.FindStringSubmatch(content)
+		if match == nil || match[0] != content || match[3] != "" {
+			b.Children = children
+			continue
+		}
+
+		key := "block:" + strings.ToLower(match[1])
+		if match[2] != "" {
+			key = "page:" + strings.ToLower(match[2])
+		}
+		if seen[key] {
+			slog.Info("[block references] embed embeds itself, skipping", "embed", content)
+			b.Children = children
+			continue
+		}
+
+		var embedded []*logseq.Block
+		if match[2] != "" {
+			page, ok := r.Graph.Page(match[2])
+			if !ok {
+				slog.Info("[block references] embedded page not found", "page", match[2])
+				b.Children = children
+				continue
+			}
+
+			b.Content = fmt.Sprintf("[[%s]]", match[2])
+			embedded = page.Blocks
+		} else {
+			block, _, ok := r.Graph.Block(match[1])
+			if !ok {
+				slog.Info("[block references] embedded block not found", "block", match[1])
+				b.Children = children
+				continue
+			}
+
+			b.Content = block.Content
+			embedded = block.Children
+		}
+
+		clones := make([]*logseq.Block, 0, len(embedded)+len(children))
+		for _, e := range embedded {
+			clone := e.Clone()
+			// the properties belong to the original blocks, e.g. their ids
+			clone.Walk(func(c *logseq.Block) error {
+				c.Properties = nil
+				return nil
+			})
+			clones = append(clones, clone)
+		}
+
+		seen[key] = true
+		clones = r.embed(clones, seen)
+		delete(seen, key)
+
+		b.Children = append(clones, children...)
+	}
+
+	return blocks
+}
+
+// resolve replaces all block references and embeds in text, depth is the depth of nested references
+func (r *BlockReferences) resolve(text string, depth int) string {
+	return replaceAllSubmatchFunc(referenceRegex, text, func(match []string) string {
+		if match[2] != "" {
+			// an inline page embed is just a link to the page
+			return fmt.Sprintf("[[%s]]", match[2])
+		}
+
+		id := match[1] + match[3]
+		block, page, ok := r.Graph.Block(id)
+		if !ok {
+			slog.Info("[block references] referenced block not found", "block", id)
+			return match[0]
+		}
+
+		label := headingPrefix.ReplaceAllString(strings.Join(strings.Fields(block.Content), " "), "")
+		if depth < maxReferenceDepth {
+			label = r.resolve(label, depth+1)
+		}
+
+		target, published := r.Pages[strings.ToLower(page)]
+		if !r.Link || !published {
+			return label
+		}
+		return link(r.Format, label, target, anchor(id))
+	})
+}
+
+// anchor returns the html id of the block with the given uuid
+func anchor(id string) string {
+	return "block-" + strings.ToLower(id)
+}
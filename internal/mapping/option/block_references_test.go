@@ -0,0 +1,114 @@
+package option_test
+
+import (
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+const (
+	quoteID  = "6650a1b2-0000-4000-8000-000000000001"
+	nestedID = "6650a1b2-0000-4000-8000-000000000002"
+	loopID   = "6650a1b2-0000-4000-8000-000000000003"
+)
+
+func newGraph(t *testing.T) *logseq.Graph {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"pages/Quotes.md":        "- # a quote by ((" + nestedID + "))\n  id:: " + quoteID + "\n\t- with a child\n- {{embed ((" + loopID + "))}}\n  id:: " + loopID + "\n",
+		"pages/People.md":        "- somebody\n  id:: " + nestedID + "\n",
+		"journals/2024_06_12.md": "- a journal entry\n",
+	}
+
+	testutil.WriteFiles(t, root, files)
+
+	g, err := logseq.LoadGraph(logseq.DefaultGraphConfig(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestBlockReferences(t *testing.T) {
+	graph := newGraph(t)
+
+	tests := []struct {
+		name  string
+		opts  config.Options
+		input string
+		want  string
+	}{
+		{
+			name:  "references are replaced with the text of the block",
+			opts:  config.Options{ResolveBlockReferences: true},
+			input: "- as said in ((" + quoteID + ")), `((" + quoteID + "))`\n",
+			want:  "- as said in a quote by somebody, `((" + quoteID + "))`\n",
+		},
+		{
+			name:  "unknown references are kept",
+			opts:  config.Options{ResolveBlockReferences: true},
+			input: "- ((00000000-0000-4000-8000-000000000000))\n",
+			want:  "- ((00000000-0000-4000-8000-000000000000))\n",
+		},
+		{
+			name:  "references to published pages are links",
+			opts:  config.Options{ResolveBlockReferences: true, LinkBlockReferences: true, MappedPages: map[string]string{"pages/People.md": "content/people.md"}},
+			input: "- ((" + nestedID + "))\n",
+			want:  "- [somebody](/people/#block-" + nestedID + ")\n",
+		},
+		{
+			name:  "referenced blocks get an anchor",
+			opts:  config.Options{ResolveBlockReferences: true, LinkBlockReferences: true},
+			input: "- somebody\n  id:: " + nestedID + "\n",
+			want:  "- somebody <a id=\"block-" + nestedID + "\"></a>\n  id:: " + nestedID + "\n",
+		},
+		{
+			name:  "block embeds are inlined with their children",
+			opts:  config.Options{ResolveBlockReferences: true},
+			input: "- {{embed ((" + quoteID + "))}}\n\t- own child\n",
+			want:  "- # a quote by somebody\n\t- with a child\n\t- own child\n",
+		},
+		{
+			name:  "page embeds are inlined",
+			opts:  config.Options{ResolveBlockReferences: true},
+			input: "- {{embed [[people]]}}\n",
+			want:  "- [[people]]\n\t- somebody\n",
+		},
+		{
+			name:  "embeds that embed themselves end",
+			opts:  config.Options{ResolveBlockReferences: true},
+			input: "- {{embed [[Quotes]]}}\n",
+			want:  "- [[Quotes]]\n\t- # a quote by somebody\n\t\t- with a child\n\t- {{embed ((" + loopID + "))}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := option.MappedPages(&tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &option.BlockReferences{Graph: graph, Mapped: pages}
+
+			enabled, err := r.IsEnabled(&tt.opts)
+			if err != nil || !enabled {
+				t.Fatalf("option is not enabled: %v", err)
+			}
+
+			page := logseq.Parse(tt.input)
+			err = r.Apply(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := page.Render(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
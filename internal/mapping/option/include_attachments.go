@@ -10,38 +10,57 @@ import (
 	"strings"
 
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
 )
 
-var ()
+var (
+	// Regular expression to match image URLs in Markdown
+	attachmentRegex = regexp.MustCompile(`!\[[^\]]*\]\(([^)]+)\)`)
+)
 
+// IncludeAttachments copies the attachments of a page to the static directory of HugoRepositoryPath, during a sync
+// this is a scratch directory, from there the attachments are added to the hugo repository along with the page
 type IncludeAttachments struct {
 	LogseqRepositoryPath string
 	HugoRepositoryPath   string
+	Graph                *logseq.GraphConfig
 }
 
 func (r *IncludeAttachments) IsEnabled(opts *config.Options) (bool, error) {
 	r.HugoRepositoryPath = opts.HugoRepositoryPath
 	r.LogseqRepositoryPath = opts.LogseqRepositoryPath
+	r.Graph = opts.Graph()
 
 	return opts.IncludeAttachments, nil
 }
 
-func (r *IncludeAttachments) Apply(input string) (string, error) {
-	// now iterate through all links, check if they're internal
-	// redirect them, etc.
-	// and hit next recursion level
-	// Regular expression to match image URLs in Markdown
-	re := regexp.MustCompile(`!\[[^\]]*\]\(([^)]+)\)`)
-
-	// Find all matches
-	matches := re.FindAllStringSubmatch(input, -1)
+func (r *IncludeAttachments) Apply(page *logseq.Page) error {
 	targetDirectory := filepath.Join(r.HugoRepositoryPath, "static")
 
 	err := r.createFolder(targetDirectory)
 	if err != nil {
-		return "", err
+		return err
 	}
 
+	// attachments in code are no attachments, so we only look at the text of the page
+	var applyErr error
+	page.ReplaceText(func(text string) string {
+		if applyErr != nil {
+			return text
+		}
+
+		text, applyErr = r.copyAttachments(text, targetDirectory)
+		return text
+	})
+
+	return applyErr
+}
+
+// copyAttachments copies all attachments that are linked in input to targetDirectory and rewrites their links for hugo
+func (r *IncludeAttachments) copyAttachments(input, targetDirectory string) (string, error) {
+	// Find all matches
+	matches := attachmentRegex.FindAllStringSubmatch(input, -1)
+
 	// Extract URLs from matches
 	for _, match := range matches {
 		if len(match) > 1 {
@@ -56,9 +75,14 @@ func (r *IncludeAttachments) Apply(input string) (string, error) {
 
 			// copy this file, which consists of the logseq repo path and match[1]
 			_, pureFilename := filepath.Split(match[1])
-			srcFile := filepath.Join(r.LogseqRepositoryPath, "assets", pureFilename)
+			srcFile := r.sourceFile(match[1])
 			targetFile := filepath.Join(targetDirectory, pureFilename)
 
+			if r.Graph.IsHidden(srcFile) {
+				slog.Warn("[include attachments option] attachment is hidden, skipping", "source_file", srcFile)
+				continue
+			}
+
 			// figure out of the file actually exists
 			if _, err := os.Stat(srcFile); os.IsNotExist(err) {
 				slog.Info("[include attachments option] source file not found", "source_file", srcFile)
@@ -80,10 +104,29 @@ func (r *IncludeAttachments) Apply(input string) (string, error) {
 		}
 	}
 
-	// this option does not modify the page, thus we'll just return the input
 	return input, nil
 }
 
+// sourceFile returns the file a link to an attachment points to, links are relative to the page (e.g. ../assets/a.png)
+// or to the root of the graph (e.g. /assets/a.png), if there is no such file the attachment is looked up in assets
+func (r *IncludeAttachments) sourceFile(link string) string {
+	if decoded, err := url.PathUnescape(link); err == nil {
+		link = decoded
+	}
+
+	file := filepath.Join(r.LogseqRepositoryPath, "pages", filepath.FromSlash(link))
+	if strings.HasPrefix(link, "/") {
+		file = filepath.Join(r.LogseqRepositoryPath, filepath.FromSlash(link))
+	}
+
+	rel, err := filepath.Rel(r.LogseqRepositoryPath, file)
+	if _, statErr := os.Stat(file); err == nil && statErr == nil && !strings.HasPrefix(rel, "..") {
+		return file
+	}
+
+	return filepath.Join(r.LogseqRepositoryPath, "assets", filepath.Base(file))
+}
+
 func (r *IncludeAttachments) createFolder(folder string) error {
 	if _, err := os.Stat(folder); os.IsNotExist(err) {
 		slog.Info("target folder does not exist")
@@ -0,0 +1,210 @@
+package option
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+var (
+	errUnknownLinkFormat       = errors.New("[links] unknown link format, use either 'markdown' or 'relref'")
+	errUnknownUnpublishedLinks = errors.New("[links] unknown policy for unpublished links, use either 'text', 'keep' or 'remove'")
+
+	// matches, in this order, [label]([[Page]]), #[[Page]], [[Page]] or [[Page|label]] and #Page
+	// a #Page tag has to be at the beginning of the text or be preceded by a whitespace, which is captured as well
+	linkRegex = regexp.MustCompile(`\[([^\[\]]*)\]\(\[\[([^\[\]]+)\]\]\)|#\[\[([^\[\]]+)\]\]|\[\[([^\[\]|]+)(?:\|([^\[\]]*))?\]\]|(^|\s)#([^\s#\[\](),.;:!?"']+)`)
+)
+
+// LinkRewriter rewrites links and tags that point to published pages to links to their hugo pages
+// links to all other pages are downgraded according to the unpublished_links policy
+type LinkRewriter struct {
+	Format      string
+	Unpublished string
+
+	// Pages maps the lower cased name of every published page to its target
+	Pages map[string]string
+
+	// Mapped maps the lower cased name of every page published by a mapping to its target (see MappedPages),
+	// they're added to Pages if links are rewritten
+	Mapped map[string]string
+}
+
+func (r *LinkRewriter) IsEnabled(opts *config.Options) (bool, error) {
+	if !opts.RewriteLinks && !opts.RemoveInternalLinks && !opts.Recursive {
+		return false, nil
+	}
+
+	r.Format = opts.LinkFormat
+	if r.Format == "" {
+		r.Format = config.LinkFormatMarkdown
+	}
+	if r.Format != config.LinkFormatMarkdown && r.Format != config.LinkFormatRelref {
+		return false, errUnknownLinkFormat
+	}
+
+	r.Unpublished = opts.UnpublishedLinks
+	if r.Unpublished == "" {
+		// the recursion alone only links the pages it publishes and keeps everything else
+		r.Unpublished = config.UnpublishedLinksKeep
+		if opts.RewriteLinks || opts.RemoveInternalLinks {
+			r.Unpublished = config.UnpublishedLinksText
+		}
+	}
+	if r.Unpublished != config.UnpublishedLinksText && r.Unpublished != config.UnpublishedLinksKeep && r.Unpublished != config.UnpublishedLinksRemove {
+		return false, errUnknownUnpublishedLinks
+	}
+
+	if r.Pages == nil {
+		r.Pages = make(map[string]string)
+	}
+
+	if opts.RewriteLinks {
+		addMappedPages(r.Pages, r.Mapped)
+	}
+
+	return true, nil
+}
+
+func (r *LinkRewriter) Apply(page *logseq.Page) error {
+	// links in code are left as they are
+	page.ReplaceText(func(text string) string {
+		return replaceAllSubmatchFunc(linkRegex, text, r.rewrite)
+	})
+
+	return nil
+}
+
+// rewrite returns the replacement of a single match of linkRegex
+func (r *LinkRewriter) rewrite(match []string) string {
+	var prefix, name, label string
+	switch {
+	case match[2] != "": // [label]([[Page]])
+		name, label = match[2], match[1]
+	case match[3] != "": // #[[Page]]
+		name, label = match[3], match[3]
+	case match[4] != "": // [[Page]] or [[Page|label]]
+		name, label = match[4], match[5]
+	default: // #Page
+		prefix, name, label = match[6], match[7], match[7]
+	}
+
+	if label == "" {
+		label = name
+	}
+
+	target, ok := r.Pages[strings.ToLower(name)]
+	if ok {
+		return prefix + link(r.Format, label, target, "")
+	}
+
+	switch r.Unpublished {
+	case config.UnpublishedLinksKeep:
+		return match[0]
+	case config.UnpublishedLinksRemove:
+		return prefix
+	default:
+		return prefix + label
+	}
+}
+
+// replaceAllSubmatchFunc replaces all matches of re in text with the return value of fn, which is given
+// the match and all of its groups, groups that didn't participate in the match are empty
+func replaceAllSubmatchFunc(re *regexp.Regexp, text string, fn func(match []string) string) string {
+	result := strings.Builder{}
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+		groups := make([]string, len(match)/2)
+		for i := range groups {
+			if match[2*i] >= 0 {
+				groups[i] = text[match[2*i]:match[2*i+1]]
+			}
+		}
+
+		result.WriteString(text[last:match[0]])
+		result.WriteString(fn(groups))
+		last = match[1]
+	}
+	result.WriteString(text[last:])
+
+	return result.String()
+}
+
+// link returns a markdown link to target (and, if given, to the anchor within it) in the given format
+func link(format, label, target, anchor string) string {
+	if anchor != "" {
+		anchor = "#" + anchor
+	}
+
+	if format == config.LinkFormatRelref {
+		return fmt.Sprintf(`[%s]({{< relref "%s%s" >}})`, label, contentPath(target), anchor)
+	}
+	return fmt.Sprintf("[%s](%s%s)", label, pageURL(target), anchor)
+}
+
+// MappedPages maps the lower cased name of the source of every mapping to its target
+// the pages of source patterns are looked up in the logseq repository, mappings of single files take precedence
+// this reads the whole logseq repository, so it's done once per sync and handed to the options as their Mapped pages
+func MappedPages(opts *config.Options) (map[string]string, error) {
+	pages := make(map[string]string)
+	graph := opts.Graph()
+	add := func(mapped map[string]string) {
+		for source, target := range mapped {
+			file := filepath.Join(opts.LogseqRepositoryPath, source)
+			if graph.IsHidden(file) {
+				continue
+			}
+
+			name := strings.ToLower(logseq.ResolvePageName(file, graph.FileNameFormat))
+			if _, ok := pages[name]; !ok {
+				pages[name] = target
+			}
+		}
+	}
+
+	add(opts.MappedPages)
+	for _, mapping := range opts.MappedPatterns {
+		mapped, err := mapping.Expand(opts.LogseqRepositoryPath)
+		if err != nil {
+			return nil, err
+		}
+		add(mapped)
+	}
+
+	return pages, nil
+}
+
+// addMappedPages adds all mapped pages to pages, unless a page of the same name is already part of it
+func addMappedPages(pages, mapped map[string]string) {
+	for name, target := range mapped {
+		if _, ok := pages[name]; !ok {
+			pages[name] = target
+		}
+	}
+}
+
+// contentPath returns the path of target relative to hugo's content directory
+func contentPath(target string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(target)), "content/")
+}
+
+// pageURL returns the url hugo publishes target under, assuming the default content directory and pretty urls
+func pageURL(target string) string {
+	p := contentPath(target)
+	p = strings.TrimSuffix(p, path.Ext(p))
+
+	if base := path.Base(p); base == "index" || base == "_index" {
+		p = path.Dir(p)
+	}
+
+	p = strings.Trim(p, "/")
+	if p == "" || p == "." {
+		return "/"
+	}
+	return "/" + p + "/"
+}
@@ -0,0 +1,110 @@
+package option_test
+
+import (
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
+)
+
+func TestLinkRewriter(t *testing.T) {
+	mapped := map[string]string{
+		"pages/Published.md": "content/posts/published.md",
+		"pages/Go.md":        "content/go/_index.md",
+	}
+
+	tests := []struct {
+		name  string
+		opts  config.Options
+		input string
+		want  string
+	}{
+		{
+			name:  "published pages become markdown links",
+			opts:  config.Options{RewriteLinks: true},
+			input: "- see [[Published]], [[published|this post]] and [a label]([[Published]])\n",
+			want:  "- see [Published](/posts/published/), [this post](/posts/published/) and [a label](/posts/published/)\n",
+		},
+		{
+			name:  "tags are rewritten",
+			opts:  config.Options{RewriteLinks: true},
+			input: "- #go and #[[Published]], no tag in a#b or [x](#anchor)\n",
+			want:  "- [go](/go/) and [Published](/posts/published/), no tag in a#b or [x](#anchor)\n",
+		},
+		{
+			name:  "relref",
+			opts:  config.Options{RewriteLinks: true, LinkFormat: config.LinkFormatRelref},
+			input: "- [[Published]]\n",
+			want:  "- [Published]({{< relref \"posts/published.md\" >}})\n",
+		},
+		{
+			name:  "unpublished pages become text by default",
+			opts:  config.Options{RewriteLinks: true},
+			input: "- [[Secret]], [label]([[Secret]]) and #secret\n",
+			want:  "- Secret, label and secret\n",
+		},
+		{
+			name:  "unpublished pages are kept",
+			opts:  config.Options{RewriteLinks: true, UnpublishedLinks: config.UnpublishedLinksKeep},
+			input: "- [[Secret]] #secret\n",
+			want:  "- [[Secret]] #secret\n",
+		},
+		{
+			name:  "unpublished pages are removed",
+			opts:  config.Options{RewriteLinks: true, UnpublishedLinks: config.UnpublishedLinksRemove},
+			input: "- a [[Secret]] b #secret\n",
+			want:  "- a  b \n",
+		},
+		{
+			name:  "remove internal links turns every link into text",
+			opts:  config.Options{RemoveInternalLinks: true},
+			input: "- [[Published]] #[[Secret]]\n",
+			want:  "- Published Secret\n",
+		},
+		{
+			name:  "code is left as it is",
+			opts:  config.Options{RewriteLinks: true},
+			input: "- `[[Published]]`\n- ```\n  #go\n  ```\n",
+			want:  "- `[[Published]]`\n- ```\n  #go\n  ```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.MappedPages = mapped
+			pages, err := option.MappedPages(&tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &option.LinkRewriter{Mapped: pages}
+
+			enabled, err := r.IsEnabled(&tt.opts)
+			if err != nil || !enabled {
+				t.Fatalf("option is not enabled: %v", err)
+			}
+
+			page := logseq.Parse(tt.input)
+			err = r.Apply(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := page.Render(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkRewriterInvalidOptions(t *testing.T) {
+	for _, opts := range []config.Options{
+		{RewriteLinks: true, LinkFormat: "html"},
+		{RewriteLinks: true, UnpublishedLinks: "hide"},
+	} {
+		_, err := (&option.LinkRewriter{}).IsEnabled(&opts)
+		if err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
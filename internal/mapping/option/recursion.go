@@ -10,6 +10,9 @@ var (
 	errNoRecursionTarget = errors.New("[recursion] no recursion path given, please add 'recursion_target' to your mapping options")
 )
 
+// Recursion holds the recursion settings of a mapping, it's no option on its own:
+// the linked pages are collected by the mapping, as they need to run through all options themselves,
+// and links to them are rewritten by LinkRewriter
 type Recursion struct {
 	Target     string
 	Depth      int
@@ -25,12 +28,11 @@ func (r *Recursion) IsEnabled(opts *config.Options) (bool, error) {
 		return false, errNoRecursionTarget
 	}
 
-	return true, nil
-}
+	r.Target = opts.RecursionTarget
+	r.SkipSource = opts.RecursionSkipSource
+	if opts.RecursionDepth > 0 {
+		r.Depth = opts.RecursionDepth
+	}
 
-func (r *Recursion) Apply(input string) (string, error) {
-	// now iterate through all links, check if they're internal
-	// redirect them, etc.
-	// and hit next recursion level
-	return input, nil
+	return true, nil
 }
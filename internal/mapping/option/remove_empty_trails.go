@@ -1,9 +1,8 @@
 package option
 
 import (
-	"strings"
-
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
 )
 
 // RemoveEmptyTrails removes empty trailing bullet points
@@ -14,16 +13,22 @@ func (r *RemoveEmptyTrails) IsEnabled(opts *config.Options) (bool, error) {
 	return opts.RemoveEmptyTrails, nil
 }
 
-func (r *RemoveEmptyTrails) Apply(input string) (string, error) {
-	// here we shall start at the end of the newline splitted string and move up each line until
-	// it is not empty (e.g., "- " or "-" or "" or " "), then return the result
-	lines := strings.Split(input, "\n")
-	for i := len(lines) - 1; i > 0; i-- {
-		// TODO: pretty sure there's nicer ways to archive this
-		if lines[i] != "- " && lines[i] != "-" && lines[i] != " " && lines[i] != "" {
-			return strings.Join(lines[:i+1], "\n"), nil
+func (r *RemoveEmptyTrails) Apply(page *logseq.Page) error {
+	page.Blocks = removeEmptyTrails(page.Blocks)
+	return nil
+}
+
+// removeEmptyTrails drops empty blocks from the end of blocks, the children of the last remaining block
+// are trimmed as well, as they're rendered at the very end of the page
+func removeEmptyTrails(blocks []*logseq.Block) []*logseq.Block {
+	for len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		last.Children = removeEmptyTrails(last.Children)
+		if !last.IsEmpty() {
+			break
 		}
+		blocks = blocks[:len(blocks)-1]
 	}
 
-	return input, nil
+	return blocks
 }
@@ -1,12 +1,11 @@
 package option
 
 import (
-	"strings"
-
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
 )
 
-// RemoveEmptyTrails removes the first level of the input file, e.g., to convert bullet points to paragraphs
+// UnindentFirstLevel removes the first level of the input file, e.g., to convert bullet points to paragraphs
 type UnindentFirstLevel struct {
 }
 
@@ -14,16 +13,15 @@ func (r *UnindentFirstLevel) IsEnabled(opts *config.Options) (bool, error) {
 	return opts.UnindentFirstLevel, nil
 }
 
-func (r *UnindentFirstLevel) Apply(input string) (string, error) {
-	// here we shall start at the end of the newline splitted string and move up each line until
-	// it is not empty (e.g., "- " or "-" or "" or " "), then return the result
-	lines := strings.Split(input, "\n")
-	for i := len(lines) - 1; i > 0; i-- {
-		// TODO: pretty sure there's nicer ways to archive this
-		if lines[i] != "- " && lines[i] != "-" && lines[i] != " " && lines[i] != "" {
-			return strings.Join(lines[:i+1], "\n"), nil
-		}
+func (r *UnindentFirstLevel) Apply(page *logseq.Page) error {
+	for _, b := range page.Blocks {
+		// top level blocks become paragraphs, their children a regular markdown list
+		b.Paragraph = true
 	}
 
-	return input, nil
+	// block properties are logseq specific and would end up as plain text within the paragraphs and lists
+	return page.Walk(func(b *logseq.Block) error {
+		b.Properties = nil
+		return nil
+	})
 }
@@ -0,0 +1,67 @@
+package option_test
+
+import (
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
+)
+
+func TestUnindentFirstLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "bullets become paragraphs",
+			input: "- first\n- second\n",
+			want:  "first\n\nsecond\n",
+		},
+		{
+			name:  "children become a list",
+			input: "- intro\n\t- one\n\t\t- nested\n\t- two\n- outro\n",
+			want:  "intro\n\n- one\n\t- nested\n- two\n\noutro\n",
+		},
+		{
+			name:  "continuation lines stay in the paragraph",
+			input: "- first line\n  second line\n- next\n",
+			want:  "first line\nsecond line\n\nnext\n",
+		},
+		{
+			name:  "code fences are kept as they are",
+			input: "- ```go\n  - not a bullet\n  id:: not a property\n  ```\n",
+			want:  "```go\n- not a bullet\nid:: not a property\n```\n",
+		},
+		{
+			name:  "block properties are removed",
+			input: "- # heading\n  id:: 6650a1b2-0000-4000-8000-000000000000\n\t- child\n\t  collapsed:: true\n",
+			want:  "# heading\n\n- child\n",
+		},
+		{
+			name:  "empty bullets are dropped",
+			input: "- first\n-\n\t- orphan\n",
+			want:  "first\n\n- orphan\n",
+		},
+		{
+			name:  "page properties and text without bullets are untouched",
+			input: "title:: foo\nplain text\n",
+			want:  "title:: foo\nplain text\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := logseq.Parse(tt.input)
+
+			err := (&option.UnindentFirstLevel{}).Apply(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := page.Render(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
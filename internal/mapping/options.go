@@ -1,44 +1,53 @@
 package mapping
 
 import (
+	"maps"
+	"strings"
+
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
 	"github.com/lakrizz/logsync/internal/mapping/option"
 )
 
 type opt interface {
 	IsEnabled(*config.Options) (bool, error)
-	Apply(string) (string, error)
+	Apply(*logseq.Page) error
 }
 
 func (l *LogseqPage) getOptions() []opt {
 	return []opt{
-		&option.Recursion{Depth: 1, SkipSource: false}, // default values
+		&option.BlockReferences{Graph: l.pipeline.graph, Pages: maps.Clone(l.pipeline.published), Mapped: l.pipeline.mapped},
 		&option.IncludeAttachments{},
+		&option.LinkRewriter{Pages: maps.Clone(l.pipeline.published), Mapped: l.pipeline.mapped},
 		&option.RemoveEmptyTrails{},
 		&option.UnindentFirstLevel{},
-		&option.IncludeAttachments{},
 	}
 }
 
 func (l *LogseqPage) parseOptions(mapping *config.Options) error {
-	output := l.InputContent
+	l.Page = logseq.Parse(l.InputContent)
 	for _, v := range l.getOptions() {
 		enabled, err := v.IsEnabled(mapping)
-		if !enabled {
-			continue
-		}
 		if err != nil {
 			return err
 		}
+		if !enabled {
+			continue
+		}
 
-		optionContent, err := v.Apply(output)
+		err = v.Apply(l.Page)
 		if err != nil {
 			return err
 		}
+	}
 
-		output = optionContent
+	// page properties end up in the front matter instead of the body
+	l.Properties = l.Page.Properties
+	l.Page.Properties = nil
+	for len(l.Page.Preamble) > 0 && strings.TrimSpace(l.Page.Preamble[0]) == "" {
+		l.Page.Preamble = l.Page.Preamble[1:]
 	}
 
-	l.ParsedContent = output
+	l.ParsedContent = l.Page.Render()
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"testing"
 
+	"github.com/lakrizz/logsync/internal/logseq"
 	"github.com/lakrizz/logsync/internal/mapping/option"
 )
 
@@ -16,10 +17,11 @@ func TestRemoveEmptyTrails(t *testing.T) {
 - so aber anders, also so in gut :D
 -`
 	ret := &option.IncludeAttachments{LogseqRepositoryPath: "/home/krizz/src/krizz.org/logsync/test", HugoRepositoryPath: "/home/krizz/src/krizz.org/logsync/test"}
-	res, err := ret.Apply(s)
+	page := logseq.Parse(s)
+	err := ret.Apply(page)
 
 	if err != nil {
 		slog.Error("error with option", "error", err)
 	}
-	log.Println(res, err)
+	log.Println(page.Render(), err)
 }
@@ -4,8 +4,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
 
 	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
 )
 
 type LogseqPage struct {
@@ -13,22 +18,134 @@ type LogseqPage struct {
 	InputContent  string
 	ParsedContent string
 	InputFilename string
+	Page          *logseq.Page      // block tree of InputContent, the options work on this
+	Properties    logseq.Properties // page properties, they are removed from Page
+	Target        string            // path of the hugo page, relative to the hugo repository
+	Linked        []*LogseqPage
+
+	skip     bool      // whether the page itself is not published, only its linked pages
+	linked   []string  // files of the linked pages of the recursion
+	title    string    // title of the page, if it's not named after its file
+	created  time.Time // date of the first commit of the page
+	modified time.Time // date of the latest commit of the page
+	pipeline *pipeline
 }
 
-func ParsePage(log *slog.Logger, filename string, mapping *config.Mapping) (*LogseqPage, error) {
+// pipeline holds everything that a page shares with the pages that are published along with it
+type pipeline struct {
+	log        *slog.Logger
+	mapping    *config.Mapping
+	repository *gogit.Repository // the logseq repository, optional
+	published  map[string]string // lower cased page name -> target of all pages published by the recursion
+	graph      *logseq.Graph     // the whole logseq graph, only loaded if block references are resolved
+	mapped     map[string]string // lower cased page name -> target of all pages published by mappings, only loaded if links are rewritten
+	index      *Index
+}
+
+// ParsePage converts a logseq page to a hugo page, if the mapping is recursive all linked pages
+// are converted as well and added to Linked, a journal is converted to its posts, which are added to Linked
+// the history of the logseq repository provides the dates of the pages, without it the current time is used
+// pages of the same sync share their index, without one the graph is read for this page alone
+func ParsePage(log *slog.Logger, filename string, mapping *config.Mapping, logseqRepository *gogit.Repository, index *Index) (*LogseqPage, error) {
+	if index == nil {
+		index = &Index{}
+	}
+
+	recursion := &option.Recursion{Depth: 1, SkipSource: false} // default values
+	recursive, err := recursion.IsEnabled(mapping.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var graph *logseq.Graph
+	if mapping.Options.ResolveBlockReferences {
+		graph, err = index.Graph(mapping.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var mapped map[string]string
+	if mapping.Options.RewriteLinks || mapping.Options.ResolveBlockReferences {
+		mapped, err = index.Mapped(mapping.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := &pipeline{log: log, mapping: mapping, repository: logseqRepository, graph: graph, mapped: mapped, index: index}
+	if mapping.Journal != nil {
+		return p.parseJournal(filename)
+	}
+
+	if !recursive {
+		return p.parsePage(filename, mapping.Target)
+	}
+
+	// links to every published page are rewritten, no matter on which page they are
+	files, err := index.Files(mapping.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := linkedPages(log, filename, files, recursion.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string)
+	for _, file := range linked {
+		targets[file] = linkedTarget(recursion.Target, file, mapping.Options)
+	}
+	if !recursion.SkipSource {
+		targets[filename] = mapping.Target
+	}
+
+	p.published = make(map[string]string)
+	for name, file := range files {
+		if target, ok := targets[file]; ok {
+			p.published[name] = target
+		}
+	}
+
+	l, err := p.parsePage(filename, mapping.Target)
+	if err != nil {
+		return nil, err
+	}
+	l.skip, l.linked = recursion.SkipSource, linked
+
+	for _, file := range linked {
+		log.Info("parsing linked logseq file", "filename", file)
+		page, err := p.parsePage(file, linkedTarget(recursion.Target, file, mapping.Options))
+		if err != nil {
+			return nil, err
+		}
+		l.Linked = append(l.Linked, page)
+	}
+
+	return l, nil
+}
+
+// parsePage converts a single logseq page
+func (p *pipeline) parsePage(filename, target string) (*LogseqPage, error) {
 	_, fn := filepath.Split(filename)
-	l := &LogseqPage{InputContent: "", ParsedContent: "", logger: log, InputFilename: fn}
+	l := &LogseqPage{InputContent: "", ParsedContent: "", logger: p.log, InputFilename: fn, Target: target, Linked: make([]*LogseqPage, 0), pipeline: p}
 	err := l.readFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	err = l.parseOptions(mapping.Options)
+	err = l.readHistory(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	err = l.addFrontMatter(mapping)
+	err = l.parseOptions(p.mapping.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.addFrontMatter(p.mapping)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +163,55 @@ func (l *LogseqPage) readFile(path string) error {
 	return nil
 }
 
+// readHistory reads the dates of the first and the latest commit of the page from the logseq repository
+func (l *LogseqPage) readHistory(path string) error {
+	l.created, l.modified = time.Now(), time.Now()
+	if l.pipeline.repository == nil {
+		return nil
+	}
+
+	worktree, err := l.pipeline.repository.Worktree()
+	if err != nil {
+		return err
+	}
+
+	file, err := filepath.Rel(worktree.Filesystem.Root(), path)
+	if err != nil {
+		return err
+	}
+
+	history, err := l.pipeline.index.History(l.pipeline.repository)
+	if err != nil {
+		return err
+	}
+
+	dates, ok := history[filepath.ToSlash(file)]
+	if !ok {
+		l.logger.Info("page was never committed, using the current time as its date", "filename", file)
+		return nil
+	}
+
+	l.created, l.modified = dates.Created, dates.Modified
+	return nil
+}
+
+// LinkedFiles returns the files of all linked pages that are published along with the page by the recursion
+func (l *LogseqPage) LinkedFiles() []string {
+	return l.linked
+}
+
+// Pages returns all pages that are published, i.e. the page itself (unless recursion_skip_source is set) and its linked pages
+func (l *LogseqPage) Pages() []*LogseqPage {
+	if l.skip {
+		return l.Linked
+	}
+	return append([]*LogseqPage{l}, l.Linked...)
+}
+
 func (l *LogseqPage) Save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0777)
+	if err != nil {
+		return err
+	}
 	return os.WriteFile(path, []byte(l.ParsedContent), 0777)
 }
@@ -0,0 +1,133 @@
+package mapping
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lakrizz/logsync/internal/logseq"
+)
+
+var (
+	// defaultProperties are added to the front matter if a mapping doesn't configure its own properties
+	defaultProperties = map[string]string{
+		"title":       "title",
+		"tags":        "tags",
+		"alias":       "aliases",
+		"description": "description",
+		"date":        "date",
+		"draft":       "draft",
+	}
+
+	// listProperties are always lists, like logseq treats them
+	listProperties = []string{"tags", "alias"}
+	// scalarProperties are never lists, even if they consist of a single page reference
+	scalarProperties = []string{"title", "description", "date", "lastmod"}
+	// dateProperties are written as dates if they can be parsed
+	dateProperties = []string{"date", "lastmod"}
+
+	// dateLayouts are tried in this order to parse dates, the first one is the journal title format of logseq
+	dateLayouts = []string{"Jan 2, 2006", "2006-01-02", time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006_01_02", "2006/01/02"}
+	// ordinalRegex matches the ordinal suffix of a day, e.g. the "th" of "Jun 12th, 2024"
+	ordinalRegex = regexp.MustCompile(`(\d)(?:st|nd|rd|th)\b`)
+
+	// pageReferenceRegex matches a single [[Page]], #[[Page]] or #Page reference
+	pageReferenceRegex = regexp.MustCompile(`^#?\[\[([^\[\]]+)\]\]$|^#([^\s#]+)$`)
+)
+
+// frontMatterProperties converts the page properties to front matter values, table maps the page properties
+// that are kept to their front matter key
+func frontMatterProperties(properties logseq.Properties, table map[string]string) map[string]any {
+	if table == nil {
+		table = defaultProperties
+	}
+
+	result := make(map[string]any)
+	for _, property := range properties {
+		key := strings.ToLower(property.Key)
+		name, ok := table[key]
+		if !ok {
+			continue
+		}
+		if name == "" {
+			name = key
+		}
+
+		result[name] = propertyValue(key, property.Value)
+	}
+
+	return result
+}
+
+// propertyValue converts the value of a page property: comma separated page references become a list of
+// page names, booleans become booleans and everything else stays a string
+func propertyValue(key, value string) any {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value
+	}
+
+	if slices.Contains(scalarProperties, key) {
+		if name, ok := pageReference(value); ok {
+			value = name
+		}
+
+		if slices.Contains(dateProperties, key) {
+			if date, ok := parseDate(value); ok {
+				return date
+			}
+		}
+		return value
+	}
+
+	items := strings.Split(value, ",")
+	names := make([]string, 0, len(items))
+	references := true
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, ok := pageReference(item)
+		if !ok {
+			references = false
+			name = item
+		}
+		names = append(names, name)
+	}
+
+	if references || slices.Contains(listProperties, key) {
+		return names
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
+
+// parseDate parses the date formats logseq and people commonly use
+func parseDate(s string) (time.Time, bool) {
+	s = ordinalRegex.ReplaceAllString(s, "$1")
+	for _, layout := range dateLayouts {
+		date, err := time.Parse(layout, s)
+		if err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// pageReference returns the name of the page s references, if it is a single page reference
+func pageReference(s string) (string, bool) {
+	match := pageReferenceRegex.FindStringSubmatch(s)
+	if match == nil {
+		return "", false
+	}
+	return match[1] + match[2], true
+}
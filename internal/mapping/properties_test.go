@@ -0,0 +1,67 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+)
+
+func TestPageProperties(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "post.md")
+	content := "tags:: [[go]], #hugo, logseq\nalias:: [[Post]]\ndraft:: true\ndescription:: about [[this]]\nicon:: 🚀\n\n- the body\n"
+	err := os.WriteFile(filename, []byte(content), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       []string
+		missing    []string
+	}{
+		{
+			name:    "default properties",
+			want:    []string{`tags = ["go", "hugo", "logseq"]`, `aliases = ["Post"]`, "draft = true", `description = "about [[this]]"`},
+			missing: []string{"icon", "::"},
+		},
+		{
+			name:       "renamed properties",
+			properties: map[string]string{"tags": "categories", "icon": ""},
+			want:       []string{`categories = ["go", "hugo", "logseq"]`, `icon = "🚀"`},
+			missing:    []string{"aliases", "draft", "description", "::"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &config.Mapping{Target: "content/post.md", Frontmatter: map[string]any{}, Properties: tt.properties, Options: &config.Options{}}
+
+			page, err := mapping.ParsePage(slog.Default(), filename, m, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range tt.want {
+				if !strings.Contains(page.ParsedContent, s+"\n") {
+					t.Errorf("%q is missing in\n%s", s, page.ParsedContent)
+				}
+			}
+
+			for _, s := range tt.missing {
+				if strings.Contains(page.ParsedContent, s) {
+					t.Errorf("%q should not be part of\n%s", s, page.ParsedContent)
+				}
+			}
+
+			if !strings.HasSuffix(page.ParsedContent, "+++\n- the body\n") {
+				t.Errorf("page properties were not removed from the body:\n%s", page.ParsedContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package mapping
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/logseq"
+	"github.com/lakrizz/logsync/internal/mapping/option"
+)
+
+// RecursionFiles returns the files of all pages the recursion of mapping publishes along with filename,
+// i.e. the pages that are linked from filename, nothing if the mapping isn't recursive
+func RecursionFiles(log *slog.Logger, filename string, mapping *config.Mapping, index *Index) ([]string, error) {
+	recursion := &option.Recursion{Depth: 1} // default values
+	recursive, err := recursion.IsEnabled(mapping.Options)
+	if err != nil || !recursive || mapping.Journal != nil {
+		return nil, err
+	}
+
+	files, err := index.Files(mapping.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	return linkedPages(log, filename, files, recursion.Depth)
+}
+
+// linkedPages collects the files of all pages that are reachable from source with at most depth links
+// every page is only collected once, so cycles end the recursion, the source page itself is never part of the result
+// files maps the lower cased name of every page to its file
+func linkedPages(log *slog.Logger, source string, files map[string]string, depth int) ([]string, error) {
+	type entry struct {
+		file  string
+		level int
+	}
+
+	result := make([]string, 0)
+	visited := map[string]bool{source: true}
+	queue := []entry{{file: source, level: 0}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.level >= depth {
+			continue
+		}
+
+		dat, err := os.ReadFile(current.file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range logseq.Parse(string(dat)).Links() {
+			file, ok := files[strings.ToLower(name)]
+			if !ok {
+				log.Debug("linked page does not exist, skipping", "page", name)
+				continue
+			}
+
+			if visited[file] {
+				continue
+			}
+			visited[file] = true
+
+			result = append(result, file)
+			queue = append(queue, entry{file: file, level: current.level + 1})
+		}
+	}
+
+	return result, nil
+}
+
+// linkedTarget returns the target of a linked page within the recursion target directory
+func linkedTarget(recursionTarget, file string, opts *config.Options) string {
+	return filepath.Join(recursionTarget, config.Slugify(opts.Slug, logseq.ResolvePageName(file, opts.Graph().FileNameFormat))+".md")
+}
@@ -0,0 +1,91 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/mapping"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestRecursion(t *testing.T) {
+	logseq := t.TempDir()
+	pages := map[string]string{
+		"Source.md":  "- reading [[Book A]] and [[missing]]\n",
+		"Book A.md":  "- see [[book b]] and back to [[Source]]\n- `[[Book C]]` is code\n",
+		"book b.md":  "- links [[Book C]] and [[Book A]]\n",
+		"Book C.md":  "- too deep\n",
+		"Unused.md":  "- not linked\n",
+		"journal.md": "- [[Source]]\n",
+	}
+	testutil.WriteFiles(t, filepath.Join(logseq, "pages"), pages)
+
+	for _, skipSource := range []bool{false, true} {
+		m := &config.Mapping{
+			Source:      "pages/Source.md",
+			Target:      "content/reading.md",
+			Frontmatter: map[string]any{},
+			Options: &config.Options{
+				Recursive:            true,
+				RecursionDepth:       2,
+				RecursionTarget:      "content/books/notes",
+				RecursionSkipSource:  skipSource,
+				LogseqRepositoryPath: logseq,
+			},
+		}
+
+		page, err := mapping.ParsePage(slog.Default(), filepath.Join(logseq, m.Source), m, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		published := make(map[string]string)
+		for _, p := range page.Pages() {
+			published[p.Target] = p.ParsedContent
+		}
+
+		want := []string{"content/books/notes/book-a.md", "content/books/notes/book-b.md"}
+		if !skipSource {
+			want = append(want, "content/reading.md")
+		}
+		if len(published) != len(want) {
+			t.Fatalf("skip source %v: unexpected pages %v", skipSource, published)
+		}
+		for _, target := range want {
+			if _, ok := published[target]; !ok {
+				t.Fatalf("skip source %v: %s was not published", skipSource, target)
+			}
+		}
+
+		if !strings.Contains(page.ParsedContent, "[Book A](/books/notes/book-a/)") || !strings.Contains(page.ParsedContent, "[[missing]]") {
+			t.Errorf("links of the source page were not rewritten: %q", page.ParsedContent)
+		}
+
+		a := published["content/books/notes/book-a.md"]
+		if !strings.Contains(a, "[book b](/books/notes/book-b/)") || !strings.Contains(a, "`[[Book C]]`") {
+			t.Errorf("links of a linked page were not rewritten: %q", a)
+		}
+
+		// the source page is only linked if it is published itself
+		if strings.Contains(a, "[Source](/reading/)") == skipSource {
+			t.Errorf("skip source %v: unexpected link to the source page: %q", skipSource, a)
+		}
+
+		b := published["content/books/notes/book-b.md"]
+		if !strings.Contains(b, "[[Book C]]") || !strings.Contains(b, "[Book A](/books/notes/book-a/)") {
+			t.Errorf("links beyond the recursion depth were rewritten: %q", b)
+		}
+	}
+}
+
+func TestRecursionWithoutTarget(t *testing.T) {
+	m := &config.Mapping{Frontmatter: map[string]any{}, Options: &config.Options{Recursive: true}}
+
+	_, err := mapping.ParsePage(slog.Default(), "does-not-matter.md", m, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing recursion target")
+	}
+}
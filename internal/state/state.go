@@ -0,0 +1,92 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// State records what logsync published last, it survives restarts so missed pushes can be caught up on
+type State struct {
+	LogseqCommit string             `json:"logseq_commit"` // last synced commit of the logseq repository
+	HugoCommit   string             `json:"hugo_commit"`   // commit logsync produced for it in the hugo repository
+	Targets      map[string]string  `json:"targets"`       // hugo target -> sha256 of its published content
+	Sources      map[string]*Source `json:"sources"`       // logseq file -> what it published
+	SyncedAt     time.Time          `json:"synced_at"`
+
+	path string
+}
+
+// Source records what the last sync published for a single logseq file
+type Source struct {
+	Targets []string `json:"targets"`          // own target of the file, the pages of its recursion or the posts of a journal
+	Linked  []string `json:"linked,omitempty"` // logseq files of the linked pages its recursion published
+}
+
+// DefaultPath returns the location of the state file of the given config in the XDG data directory
+// every config has its own state, this way several configs (e.g., of two blogs) don't overwrite each other's
+func DefaultPath(configPath string) string {
+	abs, err := filepath.Abs(configPath)
+	if err == nil {
+		configPath = abs
+	}
+
+	sum := sha256.Sum256([]byte(configPath))
+	return filepath.Join(xdg.DataHome, "logsync", "state-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load reads the state from the given file, a missing file results in an empty state
+func Load(path string) (*State, error) {
+	s := &State{Targets: make(map[string]string), Sources: make(map[string]*Source), path: path}
+
+	dat, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(dat, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Targets == nil {
+		s.Targets = make(map[string]string)
+	}
+
+	if s.Sources == nil {
+		s.Sources = make(map[string]*Source)
+	}
+
+	return s, nil
+}
+
+// Save writes the state to the file it was loaded from
+// the file is replaced atomically, so a crash never leaves a half written state behind
+func (s *State) Save() error {
+	err := os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	dat, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, dat, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
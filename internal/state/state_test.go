@@ -0,0 +1,58 @@
+package state_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/state"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logsync", "state.json")
+
+	s, err := state.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.LogseqCommit != "" || len(s.Targets) != 0 {
+		t.Fatalf("expected an empty state, got %+v", s)
+	}
+
+	s.LogseqCommit = "abc"
+	s.HugoCommit = "def"
+	s.Targets["content/foo.md"] = "123"
+	s.Sources["journals/2024_06_12.md"] = &state.Source{Targets: []string{"content/posts/first.md", "content/posts/second.md"}}
+	err = s.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := state.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.LogseqCommit != "abc" || loaded.HugoCommit != "def" || loaded.Targets["content/foo.md"] != "123" {
+		t.Fatalf("state was not restored: %+v", loaded)
+	}
+
+	if source := loaded.Sources["journals/2024_06_12.md"]; source == nil || len(source.Targets) != 2 {
+		t.Fatalf("published targets were not restored: %+v", loaded.Sources)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	abs, err := filepath.Abs("blog.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.DefaultPath("blog.json") != state.DefaultPath(abs) {
+		t.Fatal("relative and absolute path of the same config have different states")
+	}
+
+	if state.DefaultPath("blog.json") == state.DefaultPath("notes.json") {
+		t.Fatal("different configs share their state")
+	}
+}
@@ -0,0 +1,103 @@
+package syncer_test
+
+import (
+	"testing"
+)
+
+func TestAttachmentsArePushed(t *testing.T) {
+	mappings := `[{"source": "pages/a.md", "target": "content/a.md", "options": {"include_attachments": true}}]`
+	s, _, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"pages/a.md":     "- ![pic](../assets/pic.png)\n",
+		"assets/pic.png": "picture",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := hugoRemote.file("static/pic.png"); !ok || content != "picture" {
+		t.Fatalf("attachment was not pushed: %q", content)
+	}
+	if content, ok := hugoRemote.file("content/a.md"); !ok || content == "" {
+		t.Fatal("page was not pushed")
+	}
+}
+
+func TestSharedAttachmentsAreKept(t *testing.T) {
+	options := `"options": {"include_attachments": true, "remove_attachments": true}`
+	tests := []struct {
+		name     string
+		mappings string
+		pages    map[string]string
+		first    string // removed first, another page still uses the attachment
+		last     string // removed last, the attachment is unused afterwards
+	}{
+		{
+			name:     "glob",
+			mappings: `[{"source": "pages/*.md", "target": "content/{{ .Slug }}.md", ` + options + `}]`,
+			pages: map[string]string{
+				"pages/a.md": "- ![pic](../assets/pic.png)\n",
+				"pages/b.md": "- ![pic](../assets/pic.png)\n",
+			},
+			first: "pages/a.md",
+			last:  "pages/b.md",
+		},
+		{
+			name: "journal",
+			mappings: `[{"source": "pages/a.md", "target": "content/a.md", ` + options + `},
+				{"source": "journals/*.md", "target": "content/posts/{{ .Date }}-{{ .Slug }}.md", "journal": {"tag": "blog"}, ` + options + `}]`,
+			pages: map[string]string{
+				"pages/a.md":             "- ![pic](../assets/pic.png)\n",
+				"journals/2024_06_12.md": "- Post #blog\n\t- ![pic](../assets/pic.png)\n",
+			},
+			first: "pages/a.md",
+			last:  "journals/2024_06_12.md",
+		},
+		{
+			name: "recursion",
+			mappings: `[{"source": "pages/a.md", "target": "content/a.md", ` + options + `},
+				{"source": "pages/Reading.md", "target": "content/reading.md", "options": {"recursive": true, "recursion_target": "content/books", "include_attachments": true, "remove_attachments": true}}]`,
+			pages: map[string]string{
+				"pages/a.md":       "- ![pic](../assets/pic.png)\n",
+				"pages/Reading.md": "- reading [[Dune]]\n",
+				"pages/Dune.md":    "- ![pic](../assets/pic.png)\n",
+			},
+			first: "pages/a.md",
+			last:  "pages/Dune.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pages["assets/pic.png"] = "picture"
+			s, logseqRemote, hugoRemote := newSyncerWithMappings(t, tt.mappings, tt.pages)
+
+			err := s.SyncHead()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := hugoRemote.file("static/pic.png"); !ok {
+				t.Fatal("attachment was not pushed")
+			}
+
+			logseqRemote.push(map[string]string{tt.first: ""})
+			err = s.SyncHead()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := hugoRemote.file("static/pic.png"); !ok {
+				t.Fatal("attachment that is still in use was removed")
+			}
+
+			logseqRemote.push(map[string]string{tt.last: ""})
+			err = s.SyncHead()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := hugoRemote.file("static/pic.png"); ok {
+				t.Fatal("unused attachment is still published")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package syncer_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+func TestDryRun(t *testing.T) {
+	s, logseqRemote, hugoRemote := newSyncer(t)
+	initialCommits := hugoRemote.commits()
+
+	out := &bytes.Buffer{}
+	s.DryRun(out)
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "+++ b/content/foo.md") || !strings.Contains(out.String(), "+- first version") {
+		t.Fatalf("expected a diff creating content/foo.md, got:\n%s", out.String())
+	}
+
+	if hugoRemote.commits() != initialCommits {
+		t.Fatal("dry run pushed to the hugo repository")
+	}
+
+	// the next dry run only covers the changes since the previous one
+	out.Reset()
+	logseqRemote.push(map[string]string{"pages/unmapped.md": "- nope\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "content/foo.md") {
+		t.Fatalf("unexpected diff:\n%s", out.String())
+	}
+}
+
+func TestDryRunKeepsHugoWorktree(t *testing.T) {
+	s, _, hugoRemote := newSyncer(t)
+
+	// an uncommitted edit in the hugo worktree and a commit somebody else pushed in the meantime
+	testutil.WriteFiles(t, hugoRemote.clone, map[string]string{"content/draft.md": "work in progress\n"})
+	hugoRemote.push(map[string]string{"content/foo.md": "- first version\n"})
+
+	out := &bytes.Buffer{}
+	s.DryRun(out)
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(hugoRemote.clone, "content", "draft.md"))
+	if err != nil || string(content) != "work in progress\n" {
+		t.Fatalf("dry run changed the hugo worktree: %q, %v", content, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(hugoRemote.clone, "content", "foo.md")); !os.IsNotExist(err) {
+		t.Fatal("dry run pulled into the hugo worktree")
+	}
+
+	// the diff is made against the fetched upstream, which already contains content/foo.md
+	if !strings.Contains(out.String(), "--- a/content/foo.md") {
+		t.Fatalf("expected a diff against the fetched content/foo.md, got:\n%s", out.String())
+	}
+}
+
+func TestDryRunAttachments(t *testing.T) {
+	mappings := `[{"source": "pages/*.md", "target": "content/{{ .Slug }}.md", "options": {"include_attachments": true, "remove_attachments": true}}]`
+	pages := map[string]string{
+		"pages/a.md":     "- ![pic](../assets/pic.png)\n",
+		"assets/pic.png": "picture",
+	}
+
+	// the attachments a sync would add
+	s, _, _ := newSyncerWithMappings(t, mappings, pages)
+	out := &bytes.Buffer{}
+	s.DryRun(out)
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "attachment: static/pic.png") {
+		t.Fatalf("expected the attachment to be added, got:\n%s", out.String())
+	}
+
+	// the attachments a sync would remove
+	s, logseqRemote, _ := newSyncerWithMappings(t, mappings, pages)
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	s.DryRun(out)
+	logseqRemote.push(map[string]string{"pages/a.md": ""})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "--- a/content/a.md") || !strings.Contains(out.String(), "removed attachment: static/pic.png") {
+		t.Fatalf("expected the page and its attachment to be removed, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,104 @@
+package syncer_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphConfig(t *testing.T) {
+	// without :file/name-format the graph uses the legacy filename format
+	mappings := `[{"source": "books/*", "target": "content/books/{{ .Slug }}.md", "options": {"include_attachments": true}}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"logseq/config.edn":       "{:hidden [\"/pages/books.Secret.md\" \"/assets/private\"]}\n",
+		"pages/books.Dune.md":     "title:: books/Dune\n\n- ![cover](../assets/dune.png)\n",
+		"pages/books.Secret.md":   "- hidden\n",
+		"pages/books.Draft.md":    "- ![scan](../assets/private/scan.png)\n",
+		"assets/dune.png":         "png",
+		"assets/private/scan.png": "secret png",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, ok := hugoRemote.file("content/books/dune.md")
+	if !ok || !strings.Contains(content, `title = "books/Dune"`) || !strings.Contains(content, "(/dune.png)") {
+		t.Fatalf("legacy page was not published: %q", content)
+	}
+	if _, ok := hugoRemote.file("content/books/secret.md"); ok {
+		t.Fatal("hidden page was published")
+	}
+
+	// hidden attachments are not copied, so their links are left as they are
+	content, ok = hugoRemote.file("content/books/draft.md")
+	if !ok || !strings.Contains(content, "(../assets/private/scan.png)") {
+		t.Fatalf("hidden attachment was published: %q", content)
+	}
+
+	// pages that become hidden are unpublished once they change
+	logseqRemote.push(map[string]string{
+		"logseq/config.edn":   "{:hidden [\"/pages/books.Secret.md\" \"/pages/books.Dune.md\"]}\n",
+		"pages/books.Dune.md": "- changed\n",
+	})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/books/dune.md"); ok {
+		t.Fatal("page that became hidden is still published")
+	}
+}
+
+func TestTitleNamesPages(t *testing.T) {
+	mappings := `[{"source": "books/*", "target": "content/books/{{ .Slug }}.md"},
+		{"source": "pages/Reading.md", "target": "content/reading.md", "options": {"rewrite_links": true}}]`
+	s, _, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		// the filename of a legacy page can't hold the namespace, logseq names it after its title
+		"logseq/config.edn": "{:file/name-format :legacy}\n",
+		"pages/Arrakis.md":  "title:: books/Arrakis Rising\n\n- the desert\n",
+		"pages/Reading.md":  "- reading [[books/Arrakis Rising]]\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/books/arrakis-rising.md"); !ok {
+		t.Fatal("page named by its title was not published")
+	}
+
+	content, ok := hugoRemote.file("content/reading.md")
+	if !ok || !strings.Contains(content, "[books/Arrakis Rising](/books/arrakis-rising/)") {
+		t.Fatalf("link doesn't point to the published page: %q", content)
+	}
+}
+
+func TestBrokenGraphConfig(t *testing.T) {
+	mappings := `[{"source": "books/*", "target": "content/books/{{ .Slug }}.md"}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"logseq/config.edn":       "{:hidden [\"/pages/books___Secret.md\"]",
+		"pages/books___Secret.md": "- hidden\n",
+	})
+	initialCommits := hugoRemote.commits()
+
+	// without the hidden paths of the broken config.edn, the hidden page would be published
+	err := s.SyncHead()
+	if err == nil {
+		t.Fatal("expected an error for a broken config.edn")
+	}
+	if hugoRemote.commits() != initialCommits || s.LastSynced() != "" {
+		t.Fatal("sync went on with a broken config.edn")
+	}
+
+	logseqRemote.push(map[string]string{"logseq/config.edn": "{:hidden [\"/pages/books___Secret.md\"]}\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hugoRemote.file("content/books/secret.md"); ok {
+		t.Fatal("hidden page was published")
+	}
+}
@@ -0,0 +1,86 @@
+package syncer_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJournalPosts(t *testing.T) {
+	mappings := `[{"source": "journals/*.md", "target": "content/posts/{{ .Date }}-{{ .Slug }}.md", "journal": {"tag": "blog"}}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"journals/2024_06_12.md": "- First post #blog\n\t- hello\n- Second post #blog\n- private\n",
+		"journals/2024_06_13.md": "- Next day #blog\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"content/posts/2024-06-12-first-post.md", "content/posts/2024-06-12-second-post.md", "content/posts/2024-06-13-next-day.md"} {
+		if _, ok := hugoRemote.file(target); !ok {
+			t.Fatalf("%s was not published", target)
+		}
+	}
+
+	// the first post is renamed, the second one is no longer tagged
+	logseqRemote.push(map[string]string{"journals/2024_06_12.md": "- Renamed post #blog\n\t- hello again\n- Second post\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := hugoRemote.file("content/posts/2024-06-12-renamed-post.md"); !ok || !strings.Contains(content, "hello again") {
+		t.Fatalf("renamed post was not published: %q", content)
+	}
+	for _, target := range []string{"content/posts/2024-06-12-first-post.md", "content/posts/2024-06-12-second-post.md"} {
+		if _, ok := hugoRemote.file(target); ok {
+			t.Fatalf("stale post %s is still published", target)
+		}
+	}
+	if _, ok := hugoRemote.file("content/posts/2024-06-13-next-day.md"); !ok {
+		t.Fatal("post of another day was removed")
+	}
+
+	logseqRemote.push(map[string]string{"journals/2024_06_12.md": ""})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/posts/2024-06-12-renamed-post.md"); ok {
+		t.Fatal("post of a removed journal is still published")
+	}
+}
+
+func TestJournalKeepsOtherPosts(t *testing.T) {
+	mappings := `[{"source": "journals/*.md", "target": "content/posts/{{ .Date }}-{{ slug .Title }}.md", "journal": {"tag": "blog"}}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"journals/2024_06_12.md": "- First post #blog\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a post of the same day that wasn't published by logsync
+	hugoRemote.pull()
+	hugoRemote.push(map[string]string{"content/posts/2024-06-12-handwritten.md": "by hand\n"})
+
+	logseqRemote.push(map[string]string{"journals/2024_06_12.md": "- Renamed post #blog\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/posts/2024-06-12-first-post.md"); ok {
+		t.Fatal("stale post is still published")
+	}
+	if _, ok := hugoRemote.file("content/posts/2024-06-12-renamed-post.md"); !ok {
+		t.Fatal("renamed post was not published")
+	}
+	if _, ok := hugoRemote.file("content/posts/2024-06-12-handwritten.md"); !ok {
+		t.Fatal("post that wasn't published by logsync was removed")
+	}
+}
@@ -0,0 +1,30 @@
+package syncer
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Poll fetches the logseq repository every interval (plus a random jitter of up to jitter)
+// and publishes everything that changed since the last synced commit, it blocks until ctx is done
+func (s *Syncer) Poll(ctx context.Context, interval, jitter time.Duration) error {
+	for {
+		wait := interval
+		if jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(jitter)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		err := s.SyncHead()
+		if err != nil {
+			// a failed poll is retried with the next tick
+			s.log.Error("error while polling logseq repository", "error", err)
+		}
+	}
+}
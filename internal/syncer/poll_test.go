@@ -0,0 +1,46 @@
+package syncer_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSyncHead(t *testing.T) {
+	s, logseqRemote, hugoRemote := newSyncer(t)
+
+	// without a previously synced commit everything is published
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, ok := hugoRemote.file("content/foo.md")
+	if !ok || !strings.Contains(content, "first version") {
+		t.Fatalf("initial sync was not published: %q", content)
+	}
+
+	logseqRemote.push(map[string]string{"pages/foo.md": "- second version\n", "pages/unmapped.md": "- nope\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, ok = hugoRemote.file("content/foo.md")
+	if !ok || !strings.Contains(content, "second version") {
+		t.Fatalf("change was not published: %q", content)
+	}
+
+	if s.LastSynced() != logseqRemote.head() {
+		t.Fatalf("last synced commit was not recorded: %q", s.LastSynced())
+	}
+
+	logseqRemote.push(map[string]string{"pages/foo.md": ""})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/foo.md"); ok {
+		t.Fatal("removed page is still published")
+	}
+}
@@ -0,0 +1,49 @@
+package syncer_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPublishByProperty(t *testing.T) {
+	mappings := `[
+		{"source": "pages/*.md", "property": "publish:: blog", "target": "content/blog/{{ .Slug }}.md"},
+		{"source": "pages/*.md", "property": "public:: true", "target": "content/notes/{{ .Slug }}.md"}
+	]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"pages/First Post.md": "publish:: [[blog]]\n\n- hello\n",
+		"pages/Note.md":       "public:: true\n\n- a note\n",
+		"pages/Private.md":    "public:: false\n\n- secret\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := hugoRemote.file("content/blog/first-post.md"); !ok || !strings.Contains(content, "hello") {
+		t.Fatalf("blog post was not published: %q", content)
+	}
+	if _, ok := hugoRemote.file("content/notes/note.md"); !ok {
+		t.Fatal("public page was not published")
+	}
+	if _, ok := hugoRemote.file("content/notes/private.md"); ok {
+		t.Fatal("private page was published")
+	}
+
+	logseqRemote.push(map[string]string{"pages/Note.md": "- no longer public\n", "pages/Private.md": "public:: true\n\n- now public\n"})
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := hugoRemote.file("content/notes/note.md"); ok {
+		t.Fatal("page that lost its property is still published")
+	}
+	if _, ok := hugoRemote.file("content/notes/private.md"); !ok {
+		t.Fatal("page that became public was not published")
+	}
+	if _, ok := hugoRemote.file("content/blog/first-post.md"); !ok {
+		t.Fatal("unchanged page was unpublished")
+	}
+}
@@ -0,0 +1,98 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/lakrizz/logsync/internal/hugo"
+	"github.com/lakrizz/logsync/internal/mapping"
+)
+
+// addRecursiveSources adds the sources of all recursive mappings whose recursion reaches a changed file to changes,
+// this way pages that are only published as linked pages are republished (or unpublished) along with their source
+// the linked pages of the previous sync cover changed and removed pages, the current links of the sources new ones
+func (s *Syncer) addRecursiveSources(changes *hugo.ChangeSet) error {
+	changed := make(map[string]bool)
+	for _, file := range changes.Files() {
+		changed[file] = true
+	}
+
+	reaching := make([]string, 0)
+	for file, source := range s.state.Sources {
+		if source != nil && slices.ContainsFunc(source.Linked, func(linked string) bool { return changed[linked] }) {
+			reaching = append(reaching, file)
+		}
+	}
+
+	sources, err := s.recursiveSources()
+	if err != nil {
+		return err
+	}
+
+	root := s.cfg.Git.LogseqRepoPath
+	index := &mapping.Index{}
+	for _, file := range sources {
+		matched, ok := s.cfg.Match(file)
+		if !ok {
+			continue
+		}
+
+		linked, err := mapping.RecursionFiles(s.log, filepath.Join(root, file), matched, index)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range linked {
+			rel, err := filepath.Rel(root, l)
+			if err != nil {
+				return err
+			}
+
+			if changed[filepath.ToSlash(rel)] {
+				reaching = append(reaching, file)
+				break
+			}
+		}
+	}
+
+	slices.Sort(reaching)
+	for _, file := range slices.Compact(reaching) {
+		if changed[file] {
+			continue
+		}
+
+		s.log.Info("republishing page whose recursion reaches a changed page", "file", file)
+		changes.Modify(file)
+	}
+
+	return nil
+}
+
+// recursiveSources returns the existing source files of all recursive mappings
+func (s *Syncer) recursiveSources() ([]string, error) {
+	root := s.cfg.Git.LogseqRepoPath
+	result := make([]string, 0)
+	for _, m := range s.cfg.Mappings {
+		if m.Options == nil || !m.Options.Recursive || m.Journal != nil {
+			continue
+		}
+
+		if !m.IsPattern() {
+			if _, err := os.Stat(filepath.Join(root, m.Source)); err == nil {
+				result = append(result, m.Source)
+			}
+			continue
+		}
+
+		expanded, err := m.Expand(root)
+		if err != nil {
+			return nil, err
+		}
+		for source := range expanded {
+			result = append(result, source)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package syncer_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecursionFollowsLinkedPages(t *testing.T) {
+	mappings := `[{"source": "pages/Reading.md", "target": "content/reading.md", "options": {"recursive": true, "recursion_target": "content/books"}}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"pages/Reading.md": "- reading [[Dune]], [[Emma]] and [[Foundation]]\n",
+		"pages/Dune.md":    "- the spice\n",
+		"pages/Emma.md":    "- a novel\n",
+	})
+
+	sync := func(files map[string]string) {
+		t.Helper()
+		logseqRemote.push(files)
+		err := s.SyncHead()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	published := func(target, content string) bool {
+		t.Helper()
+		got, ok := hugoRemote.file(target)
+		return ok && strings.Contains(got, content)
+	}
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !published("content/books/dune.md", "the spice") || !published("content/books/emma.md", "a novel") {
+		t.Fatal("linked pages were not published")
+	}
+
+	// a linked page is changed, its source isn't
+	sync(map[string]string{"pages/Dune.md": "- the spice must flow\n"})
+	if !published("content/books/dune.md", "must flow") {
+		t.Fatal("change of a linked page was not published")
+	}
+
+	// a page the source already linked to is created
+	sync(map[string]string{"pages/Foundation.md": "- psychohistory\n"})
+	if !published("content/books/foundation.md", "psychohistory") {
+		t.Fatal("new linked page was not published")
+	}
+
+	// the source doesn't link to a page anymore
+	sync(map[string]string{"pages/Reading.md": "- reading [[Dune]] and [[Foundation]]\n"})
+	if _, ok := hugoRemote.file("content/books/emma.md"); ok {
+		t.Fatal("page that is no longer linked is still published")
+	}
+	if !published("content/books/dune.md", "must flow") {
+		t.Fatal("page that is still linked was unpublished")
+	}
+
+	// a linked page is removed
+	sync(map[string]string{"pages/Foundation.md": ""})
+	if _, ok := hugoRemote.file("content/books/foundation.md"); ok {
+		t.Fatal("removed linked page is still published")
+	}
+	if !published("content/reading.md", "Foundation") {
+		t.Fatal("source was not republished")
+	}
+}
+
+func TestSyncFilesPublishesRecursion(t *testing.T) {
+	mappings := `[{"source": "pages/Reading.md", "target": "content/reading.md", "options": {"recursive": true, "recursion_target": "content/books"}}]`
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, mappings, map[string]string{
+		"pages/Reading.md": "- reading [[Dune]]\n",
+		"pages/Dune.md":    "- the spice\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logseqRemote.push(map[string]string{"pages/Dune.md": "- the spice must flow\n"})
+	err = s.SyncFiles([]string{"pages/Dune.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, _ := hugoRemote.file("content/books/dune.md"); !strings.Contains(content, "must flow") {
+		t.Fatalf("linked page was not published: %q", content)
+	}
+}
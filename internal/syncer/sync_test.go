@@ -0,0 +1,123 @@
+package syncer_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lakrizz/logsync/internal/syncer"
+)
+
+const syncMappings = `[{"source": "pages/*.md", "target": "content/{{ .Slug }}.md"}]`
+
+func TestSyncAll(t *testing.T) {
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, syncMappings, map[string]string{
+		"pages/foo.md": "- foo\n",
+		"pages/bar.md": "- bar\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// somebody removed a published page from the hugo repository, the logseq repository didn't change
+	hugoRemote.pull()
+	hugoRemote.push(map[string]string{"content/foo.md": ""})
+
+	err = s.SyncAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := hugoRemote.file("content/foo.md"); !ok || !strings.Contains(content, "foo") {
+		t.Fatalf("page was not published again: %q", content)
+	}
+	if _, ok := hugoRemote.file("content/bar.md"); !ok {
+		t.Fatal("page is not published anymore")
+	}
+
+	if s.LastSynced() != logseqRemote.head() {
+		t.Fatalf("last synced commit was not recorded: %q", s.LastSynced())
+	}
+}
+
+func TestSyncFiles(t *testing.T) {
+	s, logseqRemote, hugoRemote := newSyncerWithMappings(t, syncMappings, map[string]string{
+		"pages/foo.md": "- foo\n",
+		"pages/bar.md": "- bar\n",
+	})
+
+	err := s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	synced := s.LastSynced()
+
+	logseqRemote.push(map[string]string{"pages/foo.md": "- new foo\n", "pages/bar.md": "- new bar\n"})
+
+	err = s.SyncFiles([]string{"pages/foo.md", "unmapped.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, _ := hugoRemote.file("content/foo.md"); !strings.Contains(content, "new foo") {
+		t.Fatalf("given page was not published: %q", content)
+	}
+	if content, _ := hugoRemote.file("content/bar.md"); strings.Contains(content, "new bar") {
+		t.Fatalf("page that wasn't given was published: %q", content)
+	}
+
+	// bar.md is not up to date, so the next sync has to start at the previously synced commit
+	if s.LastSynced() != synced {
+		t.Fatalf("last synced commit changed from %q to %q", synced, s.LastSynced())
+	}
+
+	err = s.SyncHead()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, _ := hugoRemote.file("content/bar.md"); !strings.Contains(content, "new bar") {
+		t.Fatalf("remaining change was not published: %q", content)
+	}
+}
+
+func TestSyncFilesWithoutMappedFiles(t *testing.T) {
+	s, _, hugoRemote := newSyncerWithMappings(t, syncMappings, map[string]string{"pages/foo.md": "- foo\n"})
+	initialCommits := hugoRemote.commits()
+
+	err := s.SyncFiles([]string{"journals/2024_06_12.md", "logseq/config.edn"})
+	if !errors.Is(err, syncer.ErrNoMappedFiles) {
+		t.Fatalf("expected ErrNoMappedFiles, got %v", err)
+	}
+
+	if hugoRemote.commits() != initialCommits {
+		t.Fatal("hugo repository changed")
+	}
+}
+
+func TestSyncFails(t *testing.T) {
+	s, _, hugoRemote := newSyncerWithMappings(t, syncMappings, map[string]string{"pages/foo.md": "- foo\n"})
+
+	// the hugo repository can't be pulled from or pushed to anymore
+	err := os.RemoveAll(hugoRemote.bare)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.SyncAll()
+	if err == nil {
+		t.Fatal("SyncAll succeeded without a hugo repository")
+	}
+
+	err = s.SyncFiles([]string{"pages/foo.md"})
+	if err == nil {
+		t.Fatal("SyncFiles succeeded without a hugo repository")
+	}
+
+	if s.LastSynced() != "" {
+		t.Fatalf("failed sync was recorded: %q", s.LastSynced())
+	}
+}
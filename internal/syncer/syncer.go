@@ -0,0 +1,339 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/git"
+	"github.com/lakrizz/logsync/internal/hugo"
+	"github.com/lakrizz/logsync/internal/state"
+)
+
+var (
+	ErrNoMappedFiles = errors.New("none of the given files is part of a mapping")
+)
+
+// Syncer publishes changes of the logseq repository to the hugo repository
+type Syncer struct {
+	cfg        *config.Config
+	logseqRepo *gogit.Repository
+	hugoRepo   *gogit.Repository
+	log        *slog.Logger
+
+	// syncMu makes sure only one sync touches the repositories (and the state) at a time
+	syncMu sync.Mutex
+	state  *state.State
+
+	queueMu sync.Mutex
+	pending *job
+	wake    chan struct{}
+
+	// dryRun receives the diffs of all syncs instead of the hugo repository, if set
+	dryRun io.Writer
+}
+
+func New(cfg *config.Config, logseqRepo, hugoRepo *gogit.Repository, st *state.State, log *slog.Logger) *Syncer {
+	return &Syncer{
+		cfg:        cfg,
+		logseqRepo: logseqRepo,
+		hugoRepo:   hugoRepo,
+		state:      st,
+		log:        log,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// DryRun makes all following syncs write a diff of their changes to out instead of committing and pushing them
+func (s *Syncer) DryRun(out io.Writer) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.dryRun = out
+}
+
+// PullLogseq refreshes the logseq repository
+func (s *Syncer) PullLogseq() error {
+	err := git.Pull(s.logseqRepo, s.cfg.Git.PrivateKeyPath, s.cfg.Git.PrivateKeyPassword)
+	if err != nil {
+		return fmt.Errorf("error while pulling the logseq repo: %w", err)
+	}
+
+	return nil
+}
+
+// Sync publishes all mapped files that changed between the commits before and after of the (already pulled) logseq repository
+// if resync is set, all mapped files are published regardless of the history
+func (s *Syncer) Sync(before, after string, resync bool) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	err := s.cfg.LoadGraph()
+	if err != nil {
+		return err
+	}
+
+	// check if any of the changes fit to any mapping
+	// force pushes and new branches can't be diffed reliably, so everything is synced again
+	changes, err := s.changeSetBetween(before, after, resync)
+	if err != nil {
+		return fmt.Errorf("error computing changes: %w", err)
+	}
+
+	err = s.addRecursiveSources(changes)
+	if err != nil {
+		return fmt.Errorf("error computing changes: %w", err)
+	}
+
+	changes.Filter(s.isMapped)
+
+	if changes.IsEmpty() {
+		s.log.Info("no mapped files are part of this change")
+		return s.saveState(after, nil)
+	}
+
+	result, err := s.publish(changes)
+	if err != nil {
+		return err
+	}
+
+	return s.saveState(after, result)
+}
+
+// SyncHead pulls the logseq repository and publishes everything that changed between the last synced commit and HEAD
+// e.g., to catch up on pushes that happened while logsync was not running
+// without a previously synced commit, all mapped pages are published
+func (s *Syncer) SyncHead() error {
+	err := s.PullLogseq()
+	if err != nil {
+		return err
+	}
+
+	head, err := git.Head(s.logseqRepo)
+	if err != nil {
+		return err
+	}
+
+	last := s.LastSynced()
+	if head == last {
+		s.log.Debug("logseq repository unchanged", "head", head)
+		return nil
+	}
+
+	s.log.Info("logseq repository changed", "from", last, "to", head)
+	return s.Sync(last, head, last == "")
+}
+
+// SyncAll pulls the logseq repository and publishes all mapped pages regardless of the history
+func (s *Syncer) SyncAll() error {
+	err := s.PullLogseq()
+	if err != nil {
+		return err
+	}
+
+	head, err := git.Head(s.logseqRepo)
+	if err != nil {
+		return err
+	}
+
+	return s.Sync(s.LastSynced(), head, true)
+}
+
+// SyncFiles pulls the logseq repository and publishes the given pages, e.g. after their mapping options were changed
+// pages that are published by a recursion are published along with the source of the recursion
+// the last synced commit is not changed, since other pages might not be up to date
+func (s *Syncer) SyncFiles(files []string) error {
+	err := s.PullLogseq()
+	if err != nil {
+		return err
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	err = s.cfg.LoadGraph()
+	if err != nil {
+		return err
+	}
+
+	changes := hugo.NewChangeSet()
+	for _, file := range files {
+		changes.Modify(file)
+	}
+
+	// linked pages are published by the recursion of their source
+	err = s.addRecursiveSources(changes)
+	if err != nil {
+		return fmt.Errorf("error computing changes: %w", err)
+	}
+
+	for _, file := range files {
+		if !s.isMapped(file) {
+			s.log.Warn("file is not the source of any mapping, skipping", "file", file)
+		}
+	}
+	changes.Filter(s.isMapped)
+
+	if changes.IsEmpty() {
+		return ErrNoMappedFiles
+	}
+
+	result, err := s.publish(changes)
+	if err != nil {
+		return err
+	}
+
+	return s.saveState(s.state.LogseqCommit, result)
+}
+
+// LastSynced returns the last synced commit of the logseq repository
+func (s *Syncer) LastSynced() string {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	return s.state.LogseqCommit
+}
+
+// saveState records a successful sync up to the given logseq commit
+func (s *Syncer) saveState(logseqCommit string, result *hugo.Result) error {
+	s.state.LogseqCommit = logseqCommit
+	s.state.SyncedAt = time.Now()
+
+	// a dry run only remembers how far it got for the lifetime of this process
+	if s.dryRun != nil {
+		return nil
+	}
+
+	if result != nil {
+		if result.Commit != "" {
+			s.state.HugoCommit = result.Commit
+		}
+
+		for target, hash := range result.Written {
+			s.state.Targets[target] = hash
+		}
+
+		for _, target := range result.Removed {
+			delete(s.state.Targets, target)
+		}
+
+		for file, source := range result.Sources {
+			if source == nil {
+				delete(s.state.Sources, file)
+				continue
+			}
+			s.state.Sources[file] = source
+		}
+	}
+
+	err := s.state.Save()
+	if err != nil {
+		return fmt.Errorf("error saving sync state: %w", err)
+	}
+
+	return nil
+}
+
+// publish writes the change set to the hugo repository and pushes it
+func (s *Syncer) publish(changes *hugo.ChangeSet) (*hugo.Result, error) {
+	if s.dryRun != nil {
+		// pulling resets the hugo worktree, a dry run only fetches and diffs against the fetched upstream tree
+		err := git.Fetch(s.hugoRepo, s.cfg.Git.PrivateKeyPath, s.cfg.Git.PrivateKeyPassword)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching hugo repo: %w", err)
+		}
+
+		current, err := git.UpstreamTree(s.hugoRepo)
+		if err != nil {
+			return nil, err
+		}
+
+		err = hugo.PreviewModifiedPages(changes, s.cfg, s.state.Sources, s.logseqRepo, current, s.dryRun, s.log)
+		if err != nil {
+			return nil, fmt.Errorf("error previewing modified pages: %w", err)
+		}
+		return nil, nil
+	}
+
+	// pull current hugo-repo state to prevent non-fast-foward updates
+	// since this tool might not be the only thing that changes hugo :D
+	err := git.Pull(s.hugoRepo, s.cfg.Git.PrivateKeyPath, s.cfg.Git.PrivateKeyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("error pulling hugo repo: %w", err)
+	}
+	s.log.Info("successfully pulled changes from hugo repository")
+
+	// send all new and changed files to the hugo function
+	result, err := hugo.HandleModifiedPages(changes, s.cfg, s.state.Sources, s.logseqRepo, s.hugoRepo, s.log)
+	if err != nil {
+		git.Reset(s.hugoRepo)
+		return nil, fmt.Errorf("error handling modified pages: %w", err)
+	}
+	s.log.Info("successfully created updated pages for hugo")
+
+	err = git.Push(s.hugoRepo, s.cfg.Git.PrivateKeyPath, s.cfg.Git.PrivateKeyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("error pushing changeset: %w", err)
+	}
+	s.log.Info("successfully pushed changes to hugo repository")
+
+	return result, nil
+}
+
+// changeSetBetween computes the changes between the two given commits of the logseq repository
+// if resync is set or the history is not available locally, all files of after are part of the change set
+func (s *Syncer) changeSetBetween(before, after string, resync bool) (*hugo.ChangeSet, error) {
+	changes := hugo.NewChangeSet()
+
+	if !resync {
+		diff, err := git.Diff(s.logseqRepo, before, after)
+		if err == nil {
+			for _, change := range diff {
+				switch {
+				case change.From == "":
+					changes.Modify(change.To)
+				case change.To == "":
+					changes.Remove(change.From)
+				case change.From != change.To:
+					changes.Rename(change.From, change.To)
+				default:
+					changes.Modify(change.To)
+				}
+			}
+			return changes, nil
+		}
+
+		if !errors.Is(err, git.ErrUnknownRevision) {
+			return nil, err
+		}
+		s.log.Warn("cannot diff against local history, falling back to a full resync", "before", before, "after", after, "error", err)
+	}
+
+	files, err := git.Files(s.logseqRepo, after)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		changes.Modify(file)
+	}
+
+	return changes, nil
+}
+
+// isMapped reports whether file is the source of any mapping, pages of property mappings are mapped
+// even without their property, so they are unpublished once they lose it
+func (s *Syncer) isMapped(file string) bool {
+	return len(s.cfg.Sources(file)) > 0
+}
+
+// Branch returns the full name of the checked out branch of the logseq repository
+func (s *Syncer) Branch() (string, error) {
+	return git.CurrentBranch(s.logseqRepo)
+}
@@ -0,0 +1,198 @@
+package syncer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+	gogitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/git"
+	"github.com/lakrizz/logsync/internal/state"
+	"github.com/lakrizz/logsync/internal/syncer"
+	"github.com/lakrizz/logsync/internal/testutil"
+)
+
+// remote is a bare repository together with a working copy that pushes to it
+type remote struct {
+	t      *testing.T
+	bare   string
+	writer *gogit.Repository
+	clone  string // path of the syncer's clone, if any
+}
+
+func newRemote(t *testing.T, name string, files map[string]string) *remote {
+	t.Helper()
+
+	dir := t.TempDir()
+	r := &remote{t: t, bare: filepath.Join(dir, name+".git")}
+
+	_, err := gogit.PlainInit(r.bare, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.writer, err = gogit.PlainInit(filepath.Join(dir, name), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.writer.CreateRemote(&gogitconfig.RemoteConfig{Name: "origin", URLs: []string{r.bare}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.push(files)
+	return r
+}
+
+// head returns the hash of the last pushed commit
+func (r *remote) head() string {
+	r.t.Helper()
+
+	ref, err := r.writer.Head()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return ref.Hash().String()
+}
+
+// push writes (or, for empty content, deletes) the given files, commits and pushes them to the bare repository
+func (r *remote) push(files map[string]string) {
+	r.t.Helper()
+
+	testutil.CommitFiles(r.t, r.writer, files)
+
+	err := r.writer.Push(&gogit.PushOptions{RemoteName: "origin"})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// pull updates the working copy with the commits others pushed to the bare repository
+func (r *remote) pull() {
+	r.t.Helper()
+
+	worktree, err := r.writer.Worktree()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	err = worktree.Pull(&gogit.PullOptions{RemoteName: "origin"})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		r.t.Fatal(err)
+	}
+}
+
+// file returns the content of the given file at HEAD of the bare repository
+func (r *remote) file(name string) (string, bool) {
+	r.t.Helper()
+
+	repo, err := gogit.PlainOpen(r.bare)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	f, err := commit.File(name)
+	if err != nil {
+		return "", false
+	}
+
+	content, err := f.Contents()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return content, true
+}
+
+// commits counts the commits reachable from HEAD of the bare repository
+func (r *remote) commits() int {
+	r.t.Helper()
+
+	repo, err := gogit.PlainOpen(r.bare)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	iter, err := repo.Log(&gogit.LogOptions{})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	count := 0
+	err = iter.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return count
+}
+
+// newSyncer sets up a logseq and hugo remote with local clones and a syncer that maps pages/foo.md to content/foo.md
+func newSyncer(t *testing.T) (*syncer.Syncer, *remote, *remote) {
+	t.Helper()
+
+	return newSyncerWithMappings(t, `[{"source": "pages/foo.md", "target": "content/foo.md", "frontmatter": {}, "options": {}}]`, map[string]string{"pages/foo.md": "- first version\n"})
+}
+
+// newSyncerWithMappings is newSyncer with the given mappings (as json) and initial logseq pages
+func newSyncerWithMappings(t *testing.T, mappings string, pages map[string]string) (*syncer.Syncer, *remote, *remote) {
+	t.Helper()
+
+	logseqRemote := newRemote(t, "logseq", pages)
+	hugoRemote := newRemote(t, "hugo", map[string]string{"content/.keep": "keep\n"})
+
+	dir := t.TempDir()
+	logseqPath := filepath.Join(dir, "logseq")
+	hugoPath := filepath.Join(dir, "hugo")
+
+	logseqRepo, err := git.CloneOrOpen(logseqPath, logseqRemote.bare, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hugoRepo, err := git.CloneOrOpen(hugoPath, hugoRemote.bare, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logseqRemote.clone, hugoRemote.clone = logseqPath, hugoPath
+
+	cfg := &config.Config{}
+	err = json.Unmarshal([]byte(`{
+		"git": {"hugo_repo_path": "`+hugoPath+`", "logseq_repo_path": "`+logseqPath+`", "username": "logsync", "email": "logsync@example.com"},
+		"mode": "poll",
+		"mappings": `+mappings+`
+	}`), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetStaticValuesForAllOptions()
+
+	st, err := state.Load(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return syncer.New(cfg, logseqRepo, hugoRepo, st, slog.New(slog.NewTextHandler(io.Discard, nil))), logseqRemote, hugoRemote
+}
@@ -0,0 +1,76 @@
+package syncer
+
+import (
+	"context"
+)
+
+// job is a pending sync of all changes between two commits of the logseq repository
+type job struct {
+	before string
+	after  string
+	resync bool
+}
+
+// Enqueue schedules a sync of the changes between before and after and returns immediately
+// if a job is still pending, both are merged into a single job that covers the changes of both
+func (s *Syncer) Enqueue(before, after string, resync bool) {
+	s.queueMu.Lock()
+	if s.pending == nil {
+		s.pending = &job{before: before, after: after, resync: resync}
+	} else {
+		// the pending job starts at its own before, so it now covers everything up to the new after
+		s.log.Info("merging sync with pending one", "before", s.pending.before, "after", after)
+		s.pending.after = after
+		s.pending.resync = s.pending.resync || resync
+	}
+	s.queueMu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+		// the worker is already notified
+	}
+}
+
+// Run processes all enqueued jobs one after another until ctx is done
+// a job that is running when ctx is done is finished first
+func (s *Syncer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+
+		s.queueMu.Lock()
+		j := s.pending
+		s.pending = nil
+		s.queueMu.Unlock()
+
+		if j == nil {
+			continue
+		}
+
+		err := s.run(j)
+		if err != nil {
+			s.log.Error("error while syncing", "before", j.before, "after", j.after, "error", err)
+		}
+	}
+}
+
+func (s *Syncer) run(j *job) error {
+	// refresh repository, the job only acts as a trigger
+	err := s.PullLogseq()
+	if err != nil {
+		return err
+	}
+
+	// a previously failed sync leaves a gap between the last synced commit and the job,
+	// so we start at the last synced commit whenever we know it
+	before := s.LastSynced()
+	if before == "" {
+		before = j.before
+	}
+
+	return s.Sync(before, j.after, j.resync)
+}
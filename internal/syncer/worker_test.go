@@ -0,0 +1,44 @@
+package syncer_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerMergesPendingJobs(t *testing.T) {
+	s, logseqRemote, hugoRemote := newSyncer(t)
+	initialCommits := hugoRemote.commits()
+
+	// a burst of pushes arrives before the worker gets to run
+	for i := 0; i < 10; i++ {
+		before := logseqRemote.head()
+		logseqRemote.push(map[string]string{"pages/foo.md": fmt.Sprintf("- version %d\n", i)})
+		s.Enqueue(before, logseqRemote.head(), false)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for hugoRemote.commits() == initialCommits && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if commits := hugoRemote.commits() - initialCommits; commits != 1 {
+		t.Fatalf("expected a single sync commit, got %d", commits)
+	}
+
+	content, _ := hugoRemote.file("content/foo.md")
+	if !strings.Contains(content, "version 9") {
+		t.Fatalf("latest version was not published: %q", content)
+	}
+}
@@ -0,0 +1,63 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// WriteFiles writes the given files (relative to root), missing directories are created
+func WriteFiles(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// CommitFiles writes (or, for empty content, deletes) the given files and commits them
+func CommitFiles(t testing.TB, repo *gogit.Repository, files map[string]string) string {
+	t.Helper()
+	return CommitFilesAt(t, repo, files, time.Now())
+}
+
+// CommitFilesAt is CommitFiles with a fixed author date
+func CommitFilesAt(t testing.TB, repo *gogit.Repository, files map[string]string, when time.Time) string {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if content == "" {
+			if _, err := worktree.Remove(name); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		WriteFiles(t, worktree.Filesystem.Root(), map[string]string{name: content})
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := worktree.Commit("test", &gogit.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: when}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hash.String()
+}
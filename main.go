@@ -1,167 +1,95 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"io"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-	"path"
 
 	"github.com/lakrizz/logsync/internal/config"
 	"github.com/lakrizz/logsync/internal/git"
-	"github.com/lakrizz/logsync/internal/github"
-	"github.com/lakrizz/logsync/internal/hugo"
-	"github.com/lakrizz/logsync/internal/ngrok"
+	"github.com/lakrizz/logsync/internal/state"
+	"github.com/lakrizz/logsync/internal/syncer"
 )
 
+const usage = `usage: logsync [flags] <command> [command flags] [arguments]
+
+commands:
+  serve               sync on every change of the logseq repository (default)
+  sync --all          publish all mapped pages once
+  sync <file>...      publish the given logseq files (e.g., pages/foo.md) once
+
+flags:
+`
+
 func main() {
-	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}))
-	cfg, err := config.Load()
+	flags := flag.NewFlagSet("logsync", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultPath(), "path of the config file")
+	logLevel := flags.String("log-level", "debug", "minimum level of log messages (debug, info, warn or error)")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*logLevel))
 	if err != nil {
-		log.Error("error loading config", "error", "error", err)
-		return
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true, Level: level}))
 
-	if valid, errs := cfg.IsValid(); !valid {
-		log.Error("invalid config", "hints", errs)
-		return
+	// without a command we keep the behaviour of earlier versions and serve
+	command, args := "serve", flags.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
 
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Error("error getting working directory", "error", err)
-		return
+	switch command {
+	case "serve":
+		err = serveCommand(*configPath, args, log)
+	case "sync":
+		err = syncCommand(*configPath, args, log)
+	default:
+		flags.Usage()
+		os.Exit(2)
 	}
 
-	pwd := path.Join(wd, "git", "logseq")
-	logseqRepo, err := git.CloneOrOpen(pwd, cfg.Git.LogseqRepoURL, cfg.Git.PrivateKeyPath, cfg.Git.PrivateKeyPassword)
 	if err != nil {
-		log.Error("error opening/cloning logseq repository", "error", err)
-		return
+		log.Error("logsync failed", "command", command, "error", err)
+		os.Exit(1)
 	}
-	cfg.SetLogseqRepositoryPath(pwd)
-	log.Info("logseq repository opened", "path", pwd)
+}
 
-	hugoRepo, err := git.Open(cfg.Git.HugoRepoPath)
+// setup loads and validates the config, opens both repositories and the sync state
+func setup(configPath string, validate func(*config.Config) (bool, error), log *slog.Logger) (*config.Config, *syncer.Syncer, error) {
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Error("error opening hugo repository", "error", err)
-		return
+		return nil, nil, fmt.Errorf("error loading config: %w", err)
 	}
-	log.Info("hugo repository opened")
 
-	// now we cloned the repository
-	// we want to open the reverse proxy (in this case ngrok)
-	ctx := context.Background()
-	targetURL, errChan, err := ngrok.Start(ctx, cfg, func(w http.ResponseWriter, r *http.Request) {
-		log.Info("received webhook call")
-		if r == nil {
-			log.Error("request is nil, this should not happen")
-			return
-		}
-		// 1. decode request body
-		if r.Body == nil {
-			log.Info("github webhook request has no body, skipping")
-			return
-		}
-		data, err := io.ReadAll(r.Body)
-		if err != nil {
-			log.Error("error while reading github webhook request body", "error", err)
-			return
-		}
-
-		obj := &github.PushPayload{}
-		err = json.Unmarshal(data, &obj)
-		if err != nil {
-			// handle the initial ping from github (see: https://docs.github.com/en/webhooks/webhook-events-and-payloads#ping)
-			pingObject := &github.PingPayload{}
-			if err = json.Unmarshal(data, &pingObject); err == nil {
-				log.Info("✓ github webhook ping successfully handled")
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			log.Error("error while unmarshalling github webhook payload into push struct", "error", err)
-			return
-		}
-
-		log.Info("received push in logseq repository")
-
-		// 2. check if any of the changes fit to any mapping
-		changedFiles := make([]string, 0)
-		for _, commit := range obj.Commits {
-			for _, file := range commit.Modified {
-				for _, mapping := range cfg.Mappings {
-					if mapping.Source == file {
-						changedFiles = append(changedFiles, file)
-					}
-				}
-			}
-		}
-
-		if len(changedFiles) == 0 {
-			log.Info("no mapped files are part of this push")
-			return
-		}
-
-		// 3. refresh repository and fetch all mapped pages (with depth n)
-		err = git.Pull(logseqRepo, cfg.Git.PrivateKeyPath, cfg.Git.PrivateKeyPassword)
-		if err != nil {
-			log.Error("error while pulling the logseq repo", "error", err)
-			return
-		}
-
-		// 4. convert pages to hugo
-		_, err = logseqRepo.Worktree()
-		if err != nil {
-			log.Error("error fetching the git worktree", "error", err)
-			return
-		}
-
-		// pull current hugo-repo state to prevent non-fast-foward updates
-		err = git.Pull(hugoRepo, cfg.Git.PrivateKeyPath, cfg.Git.PrivateKeyPassword)
-		if err != nil {
-			log.Error("error pulling hugo repo", "error", err)
-			return
-		}
-		log.Info("successfully pushed changes from hugo repository")
-
-		// send all new and changed files to the hugo function
-		err = hugo.HandleModifiedPages(changedFiles, cfg, logseqRepo, hugoRepo, log)
-		if err != nil {
-			log.Error("error handling modified pages", "error", err)
-			git.Reset(hugoRepo)
-			return
-		}
-		log.Info("successfully created updated pages for hugo")
-
-		// before pushing, we need to pull all changes to the hugo repo
-		// since this tool might not be the only thing that changes
-		// hugo :D
-
-		err = git.Push(hugoRepo, cfg.Git.PrivateKeyPath, cfg.Git.PrivateKeyPassword)
-		if err != nil {
-			log.Error("error pushing changeset", "error", err)
-			return
-		}
-		log.Info("successfully pushed changes to hugo repository")
-
-	})
-
-	log.Info("started ngrok", "ngrok_url", targetURL)
+	if valid, errs := validate(cfg); !valid {
+		return nil, nil, errors.Join(errors.New("invalid config"), errs)
+	}
 
+	logseqRepo, err := git.CloneOrOpen(cfg.Git.LogseqRepoPath, cfg.Git.LogseqRepoURL, cfg.Git.PrivateKeyPath, cfg.Git.PrivateKeyPassword)
 	if err != nil {
-		return
+		return nil, nil, fmt.Errorf("error opening/cloning logseq repository: %w", err)
 	}
+	log.Info("logseq repository opened", "path", cfg.Git.LogseqRepoPath)
+
+	hugoRepo, err := git.Open(cfg.Git.HugoRepoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening hugo repository: %w", err)
+	}
+	log.Info("hugo repository opened")
 
-	// now add this url as the webhook url in the repo
-	err = github.SetWebhook(ctx, cfg.Git.Token, targetURL, cfg.Git.LogseqRepoURL)
+	st, err := state.Load(state.DefaultPath(configPath))
 	if err != nil {
-		log.Error("error setting webhook", "error", err)
-		return
+		return nil, nil, fmt.Errorf("error loading sync state: %w", err)
 	}
-	log.Info("github webhook added")
 
-	<-errChan
+	return cfg, syncer.New(cfg, logseqRepo, hugoRepo, st, log), nil
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lakrizz/logsync/internal/config"
+	"github.com/lakrizz/logsync/internal/github"
+	"github.com/lakrizz/logsync/internal/ingress"
+	"github.com/lakrizz/logsync/internal/syncer"
+)
+
+// serveCommand keeps the hugo repository in sync with every change of the logseq repository until it's interrupted
+func serveCommand(configPath string, args []string, log *slog.Logger) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	cleanupWebhook := flags.Bool("cleanup-webhook", false, "remove the logsync webhook from the logseq repository on shutdown")
+	dryRun := flags.Bool("dry-run", false, "print a diff of what would be published instead of committing and pushing it")
+	flags.Parse(args)
+
+	cfg, s, err := setup(configPath, (*config.Config).IsValid, log)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		s.DryRun(os.Stdout)
+	}
+
+	// publish everything that was pushed while logsync wasn't running
+	err = s.SyncHead()
+	if err != nil {
+		return fmt.Errorf("error catching up on missed changes: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch cfg.Mode {
+	case config.ModePoll:
+		// no inbound connection needed, neither the ingress nor the github webhook are set up
+		interval, jitter := cfg.PollInterval()
+		log.Info("polling logseq repository", "interval", interval, "jitter", jitter)
+		err = s.Poll(ctx, interval, jitter)
+	default:
+		err = serveWebhooks(ctx, cfg, s, *cleanupWebhook, log)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Info("shutting down")
+	return nil
+}
+
+// serveWebhooks exposes the webhook handler via the configured ingress and registers it with github, it blocks until ctx is done
+func serveWebhooks(ctx context.Context, cfg *config.Config, s *syncer.Syncer, cleanupWebhook bool, log *slog.Logger) error {
+	// every delivery needs to be signed with this secret, if none is configured
+	// we generate one for the lifetime of this process
+	webhookSecret := cfg.Git.WebhookSecret
+	if webhookSecret == "" {
+		var err error
+		webhookSecret, err = github.GenerateSecret()
+		if err != nil {
+			return err
+		}
+		log.Info("no webhook secret configured, generated a temporary one")
+	}
+
+	// the checked out branch doesn't change, so we don't need to ask the repository on every push
+	branch, err := s.Branch()
+	if err != nil {
+		return err
+	}
+
+	dispatcher := github.NewDispatcher(webhookSecret, log)
+	dispatcher.OnPing(func(delivery string, payload *github.PingPayload) error {
+		// handle the initial ping from github (see: https://docs.github.com/en/webhooks/webhook-events-and-payloads#ping)
+		log.Info("✓ github webhook ping successfully handled", "hook_id", payload.HookID)
+		return nil
+	})
+
+	dispatcher.OnCreate(func(delivery string, payload *github.CreatePayload) error {
+		// the commits of a new branch are delivered as a push event, so there's nothing to do here
+		log.Info("ref created in logseq repository", "ref", payload.Ref, "ref_type", payload.RefType)
+		return nil
+	})
+
+	dispatcher.OnDelete(func(delivery string, payload *github.DeletePayload) error {
+		log.Info("ref deleted in logseq repository", "ref", payload.Ref, "ref_type", payload.RefType)
+		return nil
+	})
+
+	dispatcher.OnRepository(func(delivery string, payload *github.RepositoryPayload) error {
+		switch payload.Action {
+		case "renamed", "transferred":
+			log.Warn("logseq repository moved, please update logseq_repo_url in your config", "action", payload.Action, "repository", payload.Repository.FullName)
+		case "archived", "deleted", "privatized", "publicized", "unarchived", "edited", "created":
+			log.Info("logseq repository changed", "action", payload.Action, "repository", payload.Repository.FullName)
+		default:
+			log.Info("unhandled repository action", "action", payload.Action)
+		}
+		return nil
+	})
+
+	dispatcher.OnPush(func(delivery string, payload *github.PushPayload) error {
+		log.Info("received push in logseq repository", "ref", payload.Ref)
+
+		if payload.Deleted {
+			log.Info("push deleted a ref, skipping", "ref", payload.Ref)
+			return nil
+		}
+
+		if payload.Ref != branch {
+			log.Info("push is not for the checked out branch, skipping", "ref", payload.Ref, "branch", branch)
+			return nil
+		}
+
+		// the sync runs in the background, bursts of pushes are merged into a single sync
+		// force pushes and new branches can't be diffed reliably, so everything is synced again
+		s.Enqueue(payload.Before, payload.After, payload.Forced || payload.Created)
+		return nil
+	})
+
+	// the worker is stopped whenever we return, not only on interrupts, e.g. if the ingress can't be started
+	ctx, cancel := context.WithCancel(ctx)
+	workerDone := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(workerDone)
+	}()
+	defer func() {
+		// let a running sync finish before shutting down
+		cancel()
+		<-workerDone
+	}()
+
+	// we want to open the reverse proxy (e.g., ngrok)
+	in, err := ingress.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	targetURL, errChan, err := in.Start(ctx, dispatcher)
+	if err != nil {
+		return err
+	}
+	log.Info("started ingress", "type", cfg.Ingress.Type, "url", targetURL)
+
+	// now add this url as the webhook url in the repo
+	hookID, err := github.SetWebhook(ctx, cfg.Git.Token, targetURL, cfg.Git.LogseqRepoURL, webhookSecret, dispatcher.Events())
+	if err != nil {
+		return err
+	}
+	log.Info("github webhook set", "hook_id", hookID)
+
+	var ingressErr error
+	select {
+	case ingressErr = <-errChan:
+		log.Error("ingress stopped", "error", ingressErr)
+	case <-ctx.Done():
+	}
+
+	if cleanupWebhook {
+		// ctx might already be cancelled at this point
+		err = github.RemoveWebhook(context.Background(), cfg.Git.Token, cfg.Git.LogseqRepoURL)
+		if err != nil {
+			return errors.Join(ingressErr, err)
+		}
+		log.Info("github webhook removed")
+	}
+
+	if ingressErr != nil {
+		return fmt.Errorf("ingress stopped: %w", ingressErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/lakrizz/logsync/internal/config"
+)
+
+var (
+	errNothingToSync = errors.New("either pass --all or the files to sync")
+	errFlagAfterFile = errors.New("flags have to come before the files to sync, e.g. logsync sync --dry-run pages/foo.md")
+)
+
+// syncCommand publishes all or the given pages once, e.g. from cron or after changing a mapping's options
+func syncCommand(configPath string, args []string, log *slog.Logger) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	all := flags.Bool("all", false, "publish all mapped pages")
+	dryRun := flags.Bool("dry-run", false, "print a diff of what would be published instead of committing and pushing it")
+	flags.Parse(args)
+
+	// flag stops parsing at the first file, so a later flag would be taken for a file
+	for _, file := range flags.Args() {
+		if strings.HasPrefix(file, "-") {
+			return errFlagAfterFile
+		}
+	}
+
+	if *all == (flags.NArg() > 0) {
+		return errNothingToSync
+	}
+
+	_, s, err := setup(configPath, (*config.Config).IsValidForSync, log)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		s.DryRun(os.Stdout)
+	}
+
+	if *all {
+		return s.SyncAll()
+	}
+
+	return s.SyncFiles(flags.Args())
+}